@@ -1,3 +1,3 @@
 module github.com/go-framework/event
 
-go 1.14
+go 1.20
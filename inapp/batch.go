@@ -0,0 +1,155 @@
+package inapp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchHandler is the callback of batch subscription, batch is the publish Envelopes in publish order.
+// returns the BatchError when part of batch failed, other error fails the whole batch.
+type BatchHandler func(ctx context.Context, batch []*Envelope) error
+
+// BatchError is the partial failure of batch, the failed Envelopes are put into the DeadLetterSink.
+type BatchError struct {
+	Errors map[int]error // Errors is the error of failed Envelope by batch index.
+}
+
+func (e *BatchError) Error() string {
+	var indexes = make([]int, 0, len(e.Errors))
+	for idx := range e.Errors {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+
+	var list = make([]string, 0, len(indexes))
+	for _, idx := range indexes {
+		list = append(list, fmt.Sprintf("%d: %v", idx, e.Errors[idx]))
+	}
+	return fmt.Sprintf("batch %d failed: %s", len(list), strings.Join(list, "; "))
+}
+
+// SubscribeBatch subscribe event with name and batch callback f, passed option by context, returns the Subscription handle.
+// the batch is flushed when size publish buffered or wait passed from the first buffered publish,
+// not positive size or wait is unlimited. the buffered publish is flushed on Unsubscribe and Close.
+// the batch callback is done out of publish, the publish is succeeded when buffered.
+func (e *Event) SubscribeBatch(ctx context.Context, name string, size int, wait time.Duration, f BatchHandler) *Subscription {
+	if f == nil {
+		return nil
+	}
+	var b = &batcher{
+		e:    e,
+		size: size,
+		wait: wait,
+		f:    f,
+	}
+	b.cb = newCallback(atomic.AddUint64(&e.seq, 1), name, b.add, GetSubscribeOptionsFromContext(ctx))
+	b.cb.batch = b
+	return e.subscribe(b.cb)
+}
+
+// Close unsubscribe all callbacks of Event, the batch subscriptions are flushed.
+func (e *Event) Close() {
+	e.list.Range(func(key, value interface{}) bool {
+		e.unsubscribe(key.(string), nil)
+		return true
+	})
+}
+
+// batcher buffers the publish Envelopes of batch subscription.
+type batcher struct {
+	e       *Event        // e is the subscribed Event.
+	cb      *callback     // cb is the callback of batch subscription.
+	size    int           // size is the max batch size.
+	wait    time.Duration // wait is the max wait of the first buffered publish.
+	f       BatchHandler  // f is the batch callback.
+	flushMu sync.Mutex    // flushMu serializes flush in publish order.
+	mu      sync.Mutex    // mu protects fields below.
+	list    []*Envelope   // list of buffered Envelopes.
+	timer   Timer         // timer flushes when wait passed.
+	closed  bool          // closed is true when callback removed, the later publish is flushed at once.
+}
+
+// add the publish Envelope of ctx into batch, it's the callback func of batch subscription.
+func (b *batcher) add(ctx context.Context, args ...interface{}) error {
+	env, ok := GetEnvelopeFromContext(ctx)
+	if !ok {
+		env = b.e.newEnvelope(b.cb.name, nil, args)
+	}
+
+	b.mu.Lock()
+	b.list = append(b.list, env)
+	var full = b.closed || (b.size > 0 && len(b.list) >= b.size)
+	if !full && b.timer == nil && b.wait > 0 {
+		b.timer = b.e.getOptions().Clock.AfterFunc(b.wait, b.flush)
+	}
+	b.mu.Unlock()
+
+	if full {
+		b.flush()
+	}
+	return nil
+}
+
+// flush the buffered Envelopes to batch callback, the failed Envelopes are put into the DeadLetterSink.
+func (b *batcher) flush() {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	var list = b.list
+	b.list = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+	if len(list) == 0 {
+		return
+	}
+
+	var err = b.call(list)
+	if err == nil {
+		return
+	}
+	batchErr, ok := err.(*BatchError)
+	for idx, env := range list {
+		var itemErr = err
+		if ok {
+			if itemErr = batchErr.Errors[idx]; itemErr == nil {
+				continue
+			}
+		}
+		atomic.AddUint64(&b.e.stats.Failed, 1)
+
+		var ctx = context.WithValue(context.Background(), topicCtxKey{}, env.Name)
+		ctx = context.WithValue(ctx, envelopeCtxKey{}, env)
+		b.e.deadLetter(ctx, b.cb, itemErr, env.Payload...)
+	}
+}
+
+// call batch callback, recover the panic by Event panic policy.
+func (b *batcher) call(list []*Envelope) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = b.e.recovered(context.Background(), b.cb, e)
+		}
+	}()
+	return b.f(context.Background(), list)
+}
+
+// close the batcher when callback removed, the buffered Envelopes are flushed.
+func (b *batcher) close() {
+	b.mu.Lock()
+	b.closed = true
+	var pending = len(b.list) > 0
+	b.mu.Unlock()
+
+	if pending {
+		go b.flush()
+	}
+}
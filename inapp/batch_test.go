@@ -0,0 +1,134 @@
+package inapp
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEvent_SubscribeBatch(t *testing.T) {
+	var mu sync.Mutex
+	var got [][]int
+
+	record := func(ctx context.Context, batch []*Envelope) error {
+		var values []int
+		for _, env := range batch {
+			values = append(values, env.Payload[0].(int))
+		}
+		mu.Lock()
+		got = append(got, values)
+		mu.Unlock()
+		return nil
+	}
+	batches := func() [][]int {
+		mu.Lock()
+		defer mu.Unlock()
+		var result = got
+		got = nil
+		return result
+	}
+
+	t.Run("size", func(t *testing.T) {
+		var e = NewEvent()
+		s := e.SubscribeBatch(context.TODO(), "test", 3, 0, record)
+		for i := 1; i <= 7; i++ {
+			if err := e.PublishSync(context.TODO(), "test", i); err != nil {
+				t.Fatalf("PublishSync() error = %v", err)
+			}
+		}
+		if want := [][]int{{1, 2, 3}, {4, 5, 6}}; !reflect.DeepEqual(batches(), want) {
+			t.Fatalf("want %v", want)
+		}
+
+		// flush on Unsubscribe
+		s.Unsubscribe()
+		if want := [][]int{{7}}; !reflect.DeepEqual(batches(), want) {
+			t.Fatalf("want %v", want)
+		}
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		var clock = newFakeClock()
+		var e = NewEvent(WithClock(clock))
+		e.SubscribeBatch(context.TODO(), "test", 10, time.Second, record)
+		e.PublishSync(context.TODO(), "test", 1)
+		clock.Advance(time.Second / 2)
+		e.PublishSync(context.TODO(), "test", 2)
+		if list := batches(); len(list) != 0 {
+			t.Fatalf("want no batch, got %v", list)
+		}
+		clock.Advance(time.Second / 2)
+		if want := [][]int{{1, 2}}; !reflect.DeepEqual(batches(), want) {
+			t.Fatalf("want %v", want)
+		}
+		if n := clock.Pending(); n != 0 {
+			t.Fatalf("want no pending timer, got %d", n)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		var e = NewEvent()
+		e.SubscribeBatch(context.TODO(), "a", 10, 0, record)
+		e.SubscribeBatch(context.TODO(), "b", 10, 0, record)
+		e.PublishSync(context.TODO(), "a", 1)
+		e.PublishSync(context.TODO(), "b", 2)
+		e.Close()
+
+		var list = batches()
+		if len(list) != 2 {
+			t.Fatalf("want 2 batches, got %v", list)
+		}
+		if _, ok := e.list.Load("a"); ok {
+			t.Fatalf("want event removed")
+		}
+	})
+}
+
+func TestEvent_SubscribeBatchError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []int
+	}{
+		{
+			name: "partial",
+			err:  &BatchError{Errors: map[int]error{1: ErrTest}},
+			want: []int{2},
+		},
+		{
+			name: "all",
+			err:  ErrTest,
+			want: []int{1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sink = NewMemoryDeadLetters()
+			var e = NewEvent(WithDeadLetter(sink))
+			s := e.SubscribeBatch(context.TODO(), "test", 3, 0, func(ctx context.Context, batch []*Envelope) error {
+				return tt.err
+			})
+			for i := 1; i <= 3; i++ {
+				if err := e.PublishSync(context.TODO(), "test", i); err != nil {
+					t.Fatalf("PublishSync() error = %v", err)
+				}
+			}
+
+			var got []int
+			for _, letter := range sink.List() {
+				if letter.Err != ErrTest || letter.Subscription != s.ID() || letter.Event != "test" {
+					t.Fatalf("unexpected dead letter %+v", letter)
+				}
+				got = append(got, letter.Args[0].(int))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+			if stats := e.Stats(); stats.Failed != uint64(len(tt.want)) {
+				t.Fatalf("want %d failed, got %d", len(tt.want), stats.Failed)
+			}
+		})
+	}
+}
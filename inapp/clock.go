@@ -0,0 +1,38 @@
+package inapp
+
+import (
+	"time"
+)
+
+// Clock is the time source of Event, it's replaceable in test.
+// it times the publish time, replay age, subscription expiry, rate limit and retry backoff,
+// the callback timeout is the context deadline of real time.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc waits d then calls f in its own goroutine, returns the Timer to cancel the call.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the pending call of Clock AfterFunc.
+type Timer interface {
+	// Stop the call, returns false when the call already done or stopped.
+	Stop() bool
+}
+
+// SystemClock is the Clock of time package.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc calls f after d by time.AfterFunc.
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// now returns the current time of Event Clock.
+func (e *Event) now() time.Time {
+	return e.getOptions().Clock.Now()
+}
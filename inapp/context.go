@@ -68,3 +68,19 @@ func GetDataFromContext(ctx context.Context) (interface{}, bool) {
 	data, ok := ctx.Value(dataCtxKey{}).(interface{})
 	return data, ok
 }
+
+type topicCtxKey struct{}
+
+// Get the published event name from context, it's the concrete topic matched by wildcard subscribed name.
+func GetTopicFromContext(ctx context.Context) (string, bool) {
+	topic, ok := ctx.Value(topicCtxKey{}).(string)
+	return topic, ok
+}
+
+type envelopeCtxKey struct{}
+
+// Get the published Envelope from context.
+func GetEnvelopeFromContext(ctx context.Context) (*Envelope, bool) {
+	env, ok := ctx.Value(envelopeCtxKey{}).(*Envelope)
+	return env, ok
+}
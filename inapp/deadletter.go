@@ -0,0 +1,168 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotExistSubscription = errors.New("subscription not exist")
+)
+
+// DeadLetter is a terminally failed delivery of callback.
+type DeadLetter struct {
+	Event        string        // Event is the published event name.
+	Subscribed   string        // Subscribed is the subscribed event name of callback, it can be wildcard.
+	Subscription uint64        // Subscription is the Subscription id of callback.
+	Args         []interface{} // Args of publish.
+	Envelope     *Envelope     // Envelope of publish.
+	Err          error         // Err is the callback error.
+	Attempts     int           // Attempts is the count of callback done.
+	Time         time.Time     // Time is the failed time.
+}
+
+// DeadLetterSink receives the terminally failed deliveries, Put must be safe for concurrent use.
+type DeadLetterSink interface {
+	Put(letter *DeadLetter)
+}
+
+// MemoryDeadLetters is an in memory DeadLetterSink, the zero value is ready to use.
+type MemoryDeadLetters struct {
+	mu   sync.Mutex    // mu protects list.
+	list []*DeadLetter // list of dead letters in failed order.
+}
+
+// New MemoryDeadLetters.
+func NewMemoryDeadLetters() *MemoryDeadLetters {
+	return new(MemoryDeadLetters)
+}
+
+// Put dead letter.
+func (m *MemoryDeadLetters) Put(letter *DeadLetter) {
+	m.mu.Lock()
+	m.list = append(m.list, letter)
+	m.mu.Unlock()
+}
+
+// List returns the dead letters in failed order.
+func (m *MemoryDeadLetters) List() []*DeadLetter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*DeadLetter(nil), m.list...)
+}
+
+// Len returns the count of dead letters.
+func (m *MemoryDeadLetters) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.list)
+}
+
+// Clear all dead letters.
+func (m *MemoryDeadLetters) Clear() {
+	m.mu.Lock()
+	m.list = nil
+	m.mu.Unlock()
+}
+
+// Redrive all dead letters to the original subscriber of e, see Event.Redrive.
+// the failed letters are put back with the increased attempts, returns the Errors of failed letters.
+func (m *MemoryDeadLetters) Redrive(ctx context.Context, e *Event) error {
+	m.mu.Lock()
+	var list = m.list
+	m.list = nil
+	m.mu.Unlock()
+
+	var errs Errors
+	for idx, letter := range list {
+		if err := ctx.Err(); err != nil {
+			// put back the letters not redrived.
+			for _, letter := range list[idx:] {
+				m.Put(letter)
+			}
+			errs = append(errs, err)
+			break
+		}
+		attempts, err := e.redrive(ctx, letter)
+		if err == nil {
+			continue
+		}
+		var failed = *letter
+		failed.Err = err
+		failed.Attempts += attempts
+		failed.Time = e.now()
+		m.Put(&failed)
+		errs = append(errs, err)
+	}
+	return errs.Nil()
+}
+
+// Redrive the dead letter to the original subscriber, the subscriber is done with the subscribed retry policy.
+// returns ErrNotExistSubscription when the subscriber is unsubscribed, otherwise the callback error.
+// the failed redrive is not put into the DeadLetterSink.
+func (e *Event) Redrive(ctx context.Context, letter *DeadLetter) error {
+	_, err := e.redrive(ctx, letter)
+	return err
+}
+
+// redrive returns the attempts and error of dead letter callback.
+func (e *Event) redrive(ctx context.Context, letter *DeadLetter) (int, error) {
+	actual, ok := e.list.Load(letter.Subscribed)
+	if !ok {
+		return 0, ErrNotExistSubscription
+	}
+	var event = actual.(*event)
+
+	event.mu.Lock()
+	var cb *callback
+	for _, item := range event.callbacks {
+		if item.id == letter.Subscription && !item.remove {
+			cb = item
+			break
+		}
+	}
+	event.mu.Unlock()
+	if cb == nil {
+		return 0, ErrNotExistSubscription
+	}
+
+	ctx = context.WithValue(ctx, topicCtxKey{}, letter.Event)
+	if letter.Envelope != nil {
+		ctx = context.WithValue(ctx, envelopeCtxKey{}, letter.Envelope)
+	}
+
+	var err = e.deliver(ctx, cb, letter.Args...)
+	return attemptsOf(err), err
+}
+
+// deadLetter puts the failed delivery into the Event DeadLetterSink.
+func (e *Event) deadLetter(ctx context.Context, cb *callback, err error, args ...interface{}) {
+	var sink = e.getOptions().DeadLetter
+	if sink == nil || err == nil {
+		return
+	}
+
+	var letter = &DeadLetter{
+		Subscribed:   cb.name,
+		Subscription: cb.id,
+		Args:         args,
+		Err:          err,
+		Attempts:     attemptsOf(err),
+		Time:         e.now(),
+	}
+	letter.Event, _ = GetTopicFromContext(ctx)
+	letter.Envelope, _ = GetEnvelopeFromContext(ctx)
+
+	sink.Put(letter)
+}
+
+// attemptsOf returns the callback attempts of error.
+func attemptsOf(err error) int {
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		return retryErr.Attempts
+	}
+	return 1
+}
@@ -0,0 +1,71 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEvent_DeadLetter(t *testing.T) {
+	var (
+		failed = true
+		got    []interface{}
+	)
+
+	fFlaky := func(ctx context.Context, args ...interface{}) error {
+		if failed {
+			return ErrTest
+		}
+		got = append(got, args...)
+		return nil
+	}
+
+	sink := NewMemoryDeadLetters()
+	e := NewEvent(WithDeadLetter(sink))
+	e.Subscribe(context.TODO(), "order.*", f1)
+	s := e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithRetryOption(RetryPolicy{MaxAttempts: 2})), "order.*", fFlaky)
+
+	ctx := NewPublishOptionContext(context.TODO(), WithHeaderOption("trace", "t1"))
+	if err := e.PublishSync(ctx, "order.created", "arg1"); err == nil {
+		t.Fatalf("want error, got nil")
+	}
+
+	if sink.Len() != 1 {
+		t.Fatalf("want 1 dead letter, got %d", sink.Len())
+	}
+	letter := sink.List()[0]
+	if letter.Event != "order.created" || letter.Subscribed != "order.*" || letter.Subscription != s.ID() {
+		t.Fatalf("want order.created of order.* subscription %d, got %s of %s subscription %d", s.ID(), letter.Event, letter.Subscribed, letter.Subscription)
+	}
+	if letter.Attempts != 2 || !errors.Is(letter.Err, ErrTest) {
+		t.Fatalf("want 2 attempts of %v, got %d of %v", ErrTest, letter.Attempts, letter.Err)
+	}
+	if len(letter.Args) != 1 || letter.Args[0] != "arg1" || letter.Envelope.Header("trace") != "t1" {
+		t.Fatalf("want args [arg1] with trace header, got %v %v", letter.Args, letter.Envelope)
+	}
+
+	// failed redrive put back.
+	if err := sink.Redrive(context.TODO(), e); err == nil {
+		t.Fatalf("want error, got nil")
+	}
+	if sink.Len() != 1 || sink.List()[0].Attempts != 4 {
+		t.Fatalf("want 1 dead letter with 4 attempts, got %v", sink.List())
+	}
+
+	// succeed redrive to the original subscriber only.
+	failed = false
+	if err := sink.Redrive(context.TODO(), e); err != nil {
+		t.Fatalf("Redrive() error = %v", err)
+	}
+	if sink.Len() != 0 {
+		t.Fatalf("want 0 dead letter, got %d", sink.Len())
+	}
+	if len(got) != 1 || got[0] != "arg1" {
+		t.Fatalf("want args [arg1], got %v", got)
+	}
+
+	s.Unsubscribe()
+	if err := e.Redrive(context.TODO(), letter); err != ErrNotExistSubscription {
+		t.Fatalf("want error %v, got %v", ErrNotExistSubscription, err)
+	}
+}
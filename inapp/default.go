@@ -2,19 +2,40 @@ package inapp
 
 import (
 	"context"
+	"time"
 )
 
 // Default Event.
 var DefaultEvent = NewEvent()
 
-func Subscribe(ctx context.Context, event string, callback func(context.Context, ...interface{}) error) {
-	DefaultEvent.Subscribe(ctx, event, callback)
+func Subscribe(ctx context.Context, event string, callback func(context.Context, ...interface{}) error) *Subscription {
+	return DefaultEvent.Subscribe(ctx, event, callback)
+}
+
+func SubscribeBatch(ctx context.Context, event string, size int, wait time.Duration, callback BatchHandler) *Subscription {
+	return DefaultEvent.SubscribeBatch(ctx, event, size, wait, callback)
 }
 
 func Publish(ctx context.Context, event string, args ...interface{}) error {
 	return DefaultEvent.Publish(ctx, event, args...)
 }
 
+func PublishSync(ctx context.Context, event string, args ...interface{}) error {
+	return DefaultEvent.PublishSync(ctx, event, args...)
+}
+
+func Respond(ctx context.Context, event string, responder Responder) *Subscription {
+	return DefaultEvent.Respond(ctx, event, responder)
+}
+
+func Request(ctx context.Context, event string, args ...interface{}) (interface{}, error) {
+	return DefaultEvent.Request(ctx, event, args...)
+}
+
+func Gather(ctx context.Context, event string, policy GatherPolicy, args ...interface{}) ([]*GatherResult, error) {
+	return DefaultEvent.Gather(ctx, event, policy, args...)
+}
+
 func Unsubscribe(event string, callback ...func(context.Context, ...interface{}) error) {
 	DefaultEvent.Unsubscribe(event, callback...)
 }
@@ -0,0 +1,48 @@
+package inapp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+)
+
+// Envelope is the published event, it's built for every publish and got by GetEnvelopeFromContext in callback.
+type Envelope struct {
+	ID       string            // ID is the unique publish id.
+	Name     string            // Name is the published event name.
+	Time     time.Time         // Time is the publish time.
+	Sequence uint64            // Sequence is the publish sequence number in Event, starts from 1.
+	Headers  map[string]string // Headers of publish, it's not nil.
+	Payload  []interface{}     // Payload is the publish args.
+}
+
+// Header returns the header value of key.
+func (env *Envelope) Header(key string) string {
+	return env.Headers[key]
+}
+
+// new Envelope of publish, headers are copied.
+func (e *Event) newEnvelope(name string, headers map[string]string, args []interface{}) *Envelope {
+	env := &Envelope{
+		ID:       newID(),
+		Name:     name,
+		Time:     e.now(),
+		Sequence: atomic.AddUint64(&e.pubSeq, 1),
+		Headers:  make(map[string]string, len(headers)),
+		Payload:  args,
+	}
+	for key, value := range headers {
+		env.Headers[key] = value
+	}
+	return env
+}
+
+// newID returns a random 128 bits hex id.
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,64 @@
+package inapp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEvent_PublishEnvelope(t *testing.T) {
+	var envs []*Envelope
+
+	fEnvelope := func(ctx context.Context, args ...interface{}) error {
+		env, ok := GetEnvelopeFromContext(ctx)
+		if !ok {
+			return ErrUnexpected
+		}
+		envs = append(envs, env)
+		return nil
+	}
+
+	e := NewEvent()
+	e.Subscribe(context.TODO(), "order.*", fEnvelope)
+
+	var (
+		start   = time.Now()
+		headers = map[string]string{"trace": "t1"}
+		ctx     = NewPublishOptionContext(context.TODO(), WithHeadersOption(headers), WithHeaderOption("user", "u1"))
+	)
+	if err := e.PublishSync(ctx, "order.created", "arg1", 2); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if err := e.PublishSync(context.TODO(), "order.updated"); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+
+	if len(envs) != 2 {
+		t.Fatalf("want 2 envelopes, got %d", len(envs))
+	}
+	env := envs[0]
+	if env.ID == "" || env.ID == envs[1].ID {
+		t.Fatalf("want unique id, got %s and %s", env.ID, envs[1].ID)
+	}
+	if env.Name != "order.created" || envs[1].Name != "order.updated" {
+		t.Fatalf("want names order.created and order.updated, got %s and %s", env.Name, envs[1].Name)
+	}
+	if env.Time.Before(start) || env.Time.After(time.Now()) {
+		t.Fatalf("want publish time, got %v", env.Time)
+	}
+	if env.Sequence != 1 || envs[1].Sequence != 2 {
+		t.Fatalf("want sequence 1 and 2, got %d and %d", env.Sequence, envs[1].Sequence)
+	}
+	if env.Header("trace") != "t1" || env.Header("user") != "u1" || len(envs[1].Headers) != 0 {
+		t.Fatalf("want headers, got %v and %v", env.Headers, envs[1].Headers)
+	}
+	if len(env.Payload) != 2 || env.Payload[0] != "arg1" || env.Payload[1] != 2 {
+		t.Fatalf("want payload [arg1 2], got %v", env.Payload)
+	}
+
+	// headers are copied.
+	env.Headers["trace"] = "t2"
+	if headers["trace"] != "t1" {
+		t.Fatalf("want publish headers not changed, got %v", headers)
+	}
+}
@@ -1,9 +1,20 @@
 package inapp
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 )
 
+var (
+	ErrCallbackPanic   = errors.New("callback panic")
+	ErrPublishCanceled = errors.New("publish canceled")
+	ErrStrictAborted   = errors.New("strict mode aborted")
+	// ErrRecursivePublish is reported when a callback publish synchronously to the event it's dispatched by.
+	ErrRecursivePublish = errors.New("recursive publish to dispatching event")
+)
+
 // Errors is error interface array, and impl error interface.
 type Errors []error
 
@@ -20,9 +31,47 @@ func (list Errors) Error() string {
 	return buf.String()
 }
 
+// Unwrap returns the error list, errors.Is and errors.As match any of them.
+func (list Errors) Unwrap() []error {
+	return list
+}
+
 func (list Errors) Nil() error {
 	if len(list) == 0 {
 		return nil
 	}
 	return list
 }
+
+// DeliveryError is the callback error of publish with the subscriber identity.
+type DeliveryError struct {
+	Event        string // Event is the published event name.
+	Subscribed   string // Subscribed is the subscribed event name of callback, it can be wildcard.
+	Subscription uint64 // Subscription is the Subscription id of callback.
+	Attempt      int    // Attempt is the count of callback done.
+	Err          error  // Err is the callback error.
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("event %s subscription %d of %s attempt %d: %v", e.Event, e.Subscription, e.Subscribed, e.Attempt, e.Err)
+}
+
+// Unwrap returns the callback error.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// deliveryError returns the DeliveryError of callback error, returns nil when err is nil.
+func deliveryError(ctx context.Context, cb *callback, err error) error {
+	if err == nil {
+		return nil
+	}
+	var topic, _ = GetTopicFromContext(ctx)
+	return &DeliveryError{
+		Event:        topic,
+		Subscribed:   cb.name,
+		Subscription: cb.id,
+		Attempt:      attemptsOf(err),
+		Err:          err,
+	}
+}
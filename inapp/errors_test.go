@@ -0,0 +1,37 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestErrors_Unwrap(t *testing.T) {
+	var timeout = &DeliveryError{Event: "test", Subscribed: "test", Subscription: 1, Attempt: 1, Err: ErrCallbackTimeout}
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{name: "empty", err: Errors{}, target: ErrTest, want: false},
+		{name: "item", err: Errors{ErrPanic, ErrTest}, target: ErrTest, want: true},
+		{name: "delivery error", err: Errors{timeout}, target: ErrCallbackTimeout, want: true},
+		{name: "panic value", err: newPanicError(ErrPanic), target: ErrPanic, want: true},
+		{name: "panic sentinel", err: newPanicError("boom"), target: ErrCallbackPanic, want: true},
+		{name: "not matched", err: Errors{timeout}, target: context.Canceled, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Fatalf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+
+	var deliveryErr *DeliveryError
+	if !errors.As(Errors{ErrTest, timeout}, &deliveryErr) || deliveryErr != timeout {
+		t.Fatalf("want delivery error %v, got %v", timeout, deliveryErr)
+	}
+}
@@ -4,35 +4,101 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"reflect"
+	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
-	ErrNotExistEvent = errors.New("event not exist")
+	ErrNotExistEvent   = errors.New("event not exist")
+	ErrCallbackTimeout = errors.New("callback timeout")
 )
 
+// defaultEventOptions used by the zero value Event.
+var defaultEventOptions = GetDefaultEventOptions()
+
 // Event is a inapp name. subscribe name into inbox, when publish added to list.
 type Event struct {
-	list sync.Map // the active event list. map[string]*event
+	list         sync.Map                 // the active event list. map[string]*event
+	topics       topicTree                // topics index the wildcard event names of list.
+	queues       sync.Map                 // the publish queues in ordered mode. map[string]*serialQueue
+	seq          uint64                   // seq is the last subscription id.
+	pubSeq       uint64                   // pubSeq is the last publish sequence number.
+	retained     sync.Map                 // the retained Envelope of event name. map[string]*Envelope
+	historyMu    sync.Mutex               // historyMu protects history, it's held by publish and replay Subscribe.
+	history      map[string]*replayBuffer // the recent Envelopes of event name.
+	recorded     int                      // recorded count since the last expired history sweep.
+	mu           sync.RWMutex             // mu protects middleware and interceptors.
+	middleware   []Middleware             // middleware of all callbacks.
+	interceptors []PublishInterceptor     // interceptors of publish.
+	stats        Stats                    // stats of Event, the counters are updated atomically.
+	options      *EventOptions            // options of Event, nil is the default.
+}
+
+// New Event with options.
+func NewEvent(opts ...EventOption) *Event {
+	options := GetDefaultEventOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &Event{
+		options: options,
+	}
 }
 
-// New Event.
-func NewEvent() *Event {
-	return new(Event)
+// getOptions returns the Event options, returns default when not set.
+func (e *Event) getOptions() *EventOptions {
+	if e.options == nil {
+		return defaultEventOptions
+	}
+	return e.options
 }
 
-// Subscribe event with name and callback func f, passed option by context.
-func (e *Event) Subscribe(ctx context.Context, name string, f func(context.Context, ...interface{}) error) {
+// Subscribe event with name and callback func f, passed option by context, returns the Subscription handle.
+// name can contain wildcard levels, see TopicWildcard and TopicMultiWildcard.
+func (e *Event) Subscribe(ctx context.Context, name string, f func(context.Context, ...interface{}) error) *Subscription {
 	if f == nil {
-		return
+		return nil
 	}
-	cb := &callback{
-		f:                f,
-		subscribeOptions: GetSubscribeOptionsFromContext(ctx),
+	return e.subscribe(newCallback(atomic.AddUint64(&e.seq, 1), name, f, GetSubscribeOptionsFromContext(ctx)))
+}
+
+// subscribe the callback, returns the Subscription handle.
+func (e *Event) subscribe(cb *callback) *Subscription {
+	var name = cb.name
+	cb.limiter = newLimiter(e.getOptions().Clock, cb.subscribeOptions, func(env *Envelope) {
+		e.deliverDeferred(cb, env)
+	})
+
+	var event *event
+	if e.replayable(cb) {
+		// mutex with publish, the history and live publish hand over without gap
+		e.historyMu.Lock()
+		cb.pending, cb.replayed = e.recent(name, cb.subscribeOptions), atomic.LoadUint64(&e.pubSeq)
+		event = e.register(name, cb)
+		e.historyMu.Unlock()
+		e.deliverReplay(event, cb)
+	} else {
+		event = e.register(name, cb)
+	}
+
+	e.expire(event, cb)
+	if cb.limiter != nil {
+		cb.limiter.start()
 	}
+	e.deliverRetained(event, cb)
+
+	return newSubscription(e, name, cb)
+}
 
+// register callback into the event of name, returns the event.
+func (e *Event) register(name string, cb *callback) *event {
 	actual, ok := e.list.LoadOrStore(name, &event{
+		name:      name,
 		doneLock:  make(chan struct{}, 1),
 		callbacks: callbacks{cb},
 	})
@@ -40,109 +106,439 @@ func (e *Event) Subscribe(ctx context.Context, name string, f func(context.Conte
 	var event = actual.(*event)
 
 	if !ok {
+		event.mu.Lock()
 		event.doneLock <- struct{}{}
-		return
+		if IsWildcardTopic(name) {
+			e.topics.insert(name)
+		}
+		event.mu.Unlock()
+	} else {
+		event.mu.Lock()
+		// mutex with Unsubscribe
+		event.callbacks = event.callbacks.insert(cb) // insert by priority
+		if event.doneLock == nil {
+			event.doneLock = make(chan struct{}, 1)
+			event.doneLock <- struct{}{}
+		}
+		e.list.LoadOrStore(name, event)
+		if IsWildcardTopic(name) {
+			e.topics.insert(name)
+		}
+		event.mu.Unlock()
 	}
+	return event
+}
 
-	event.mu.Lock()
-	// mutex with Unsubscribe
-	// if exist the same f then replace the latest callback.
-	if !event.callbacks.replace(cb) {
-		event.callbacks = append(event.callbacks, cb) // append
+// Publish event with args and publish option by context to async done callbacks, will be remove Once subscribed.
+// the Envelope is retained for the later subscribers when publish with Retain option or replay buffer, returns nil when no subscriber.
+// returns the context error when ctx is done, the remaining callbacks are skipped when ctx is done in dispatch.
+// the callbacks of wildcard event names which matched name are done too, got name by GetTopicFromContext.
+// callbacks are done by the Event Executor, returns the Executor error when it can't accept.
+// the publish is intercepted by Event interceptors before dispatch.
+func (e *Event) Publish(ctx context.Context, name string, args ...interface{}) error {
+	return e.intercept(e.publishAsync)(ctx, name, args...)
+}
+
+// publishAsync is the Publish PublishHandler.
+func (e *Event) publishAsync(ctx context.Context, name string, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	if event.doneLock == nil {
-		event.doneLock = make(chan struct{}, 1)
-		event.doneLock <- struct{}{}
+
+	var publishOptions = GetPublishOptionsFromContext(ctx)
+	var env, events, discard = e.prepare(name, publishOptions, args)
+	if len(events) == 0 {
+		e.published(name)
+		if publishOptions.Retain || e.replayEnabled() {
+			return nil
+		}
+		return ErrNotExistEvent
 	}
-	e.list.LoadOrStore(name, event)
-	event.mu.Unlock()
+
+	// done
+	var options = e.getOptions()
+	var queued = e.now()
+	var done = func() {
+		options.Metrics.QueueWait(name, e.now().Sub(queued))
+		e.publish(e.detach(ctx), env, events, publishOptions, func(err error) {
+			if publishOptions.Err != nil {
+				publishOptions.Err <- err
+			}
+		})
+	}
+
+	// the rejected publish is not retained, recorded and counted
+	if err := e.execute(name, publishOptions, done); err != nil {
+		discard()
+		return err
+	}
+	e.published(name)
+	return nil
 }
 
-// Publish event with args and publish option by context to async done callbacks, will be remove Once subscribed.
-func (e *Event) Publish(ctx context.Context, name string, args ...interface{}) error {
-	actual, ok := e.list.Load(name)
-	if !ok {
+// execute the publish task by Executor, the task is pushed into the name queue in ordered mode.
+func (e *Event) execute(name string, publishOptions *PublishOptions, task func()) error {
+	var options = e.getOptions()
+	if !options.Ordered && publishOptions.PartitionKey == "" {
+		return options.Executor.Execute(task)
+	}
+
+	// ordered mode push into the name queue
+	for {
+		actual, _ := e.queues.LoadOrStore(name, new(serialQueue))
+		ok, err := actual.(*serialQueue).push(options.Executor, task, func() {
+			e.queues.Delete(name)
+		})
+		if ok {
+			return err
+		}
+		// wait the drained queue removed
+		runtime.Gosched()
+	}
+}
+
+// PublishSync event with args and publish option by context, done callbacks in the caller goroutine and returns the callbacks error.
+// it's the same as Publish except the publish option Err is ignored, and it waits the partitioned callbacks done.
+// the callback publish to the event it's dispatched by is reported as ErrRecursivePublish, because it would wait the callback itself.
+func (e *Event) PublishSync(ctx context.Context, name string, args ...interface{}) error {
+	return e.intercept(e.publishSync)(ctx, name, args...)
+}
+
+// publishSync is the PublishSync PublishHandler.
+func (e *Event) publishSync(ctx context.Context, name string, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var publishOptions = GetPublishOptionsFromContext(ctx)
+	var env, events, _ = e.prepare(name, publishOptions, args)
+	e.published(name)
+	if len(events) == 0 {
+		if publishOptions.Retain || e.replayEnabled() {
+			return nil
+		}
 		return ErrNotExistEvent
 	}
 
-	var event = actual.(*event)
+	var result = make(chan error, 1)
+	e.publish(ctx, env, events, publishOptions, func(err error) {
+		result <- err
+	})
 
-	// callback done
-	done := func(ctx context.Context, args ...interface{}) {
-		var publishOptions = GetPublishOptionsFromContext(ctx)
-		var err error
+	return <-result
+}
 
-		defer func() {
-			if e := recover(); e != nil {
-				switch v := e.(type) {
-				case error:
-					err = v
-				default:
-					err = fmt.Errorf("%v", e)
-				}
+// publish Envelope payload to the matched events in order, done reports the callback error in strict mode, otherwise the Errors.
+func (e *Event) publish(ctx context.Context, env *Envelope, events []*event, publishOptions *PublishOptions, done func(error)) {
+	var metrics = e.getOptions().Metrics
+	metrics.Dispatch(env.Name, 1)
+	var p = newPublication(publishOptions.Strict, func(err error) {
+		metrics.Dispatch(env.Name, -1)
+		done(err)
+	})
+
+	defer func() {
+		var err error
+		if e := recover(); e != nil {
+			switch v := e.(type) {
+			case *PanicError: // re-panic by panic policy after the publish reported
+				p.finish(v)
+				panic(v)
+			case error:
+				err = v
+			default:
+				err = fmt.Errorf("%v", e)
 			}
+		}
+		p.finish(err)
+	}()
 
-			if publishOptions.Err != nil {
-				publishOptions.Err <- err
+	ctx = context.WithValue(ctx, topicCtxKey{}, env.Name)
+	ctx = context.WithValue(ctx, envelopeCtxKey{}, env)
+	// the request reply is only set by the request publish, not the publish in callbacks
+	if r, _ := ctx.Value(replyCtxKey{}).(*reply); r != nil && !r.claim(env.Name) {
+		ctx = context.WithValue(ctx, replyCtxKey{}, (*reply)(nil))
+	}
+
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			p.cancel(err)
+		}
+		if p.aborted() {
+			return
+		}
+		e.dispatch(ctx, event, nil, p, publishOptions.PartitionKey, env.Payload...)
+	}
+}
+
+// prepare the publish Envelope of args and returns the matched events.
+// the Envelope is recorded in history when replay buffer enabled, discard undoes the retain and record when publish rejected.
+func (e *Event) prepare(name string, publishOptions *PublishOptions, args []interface{}) (env *Envelope, events []*event, discard func()) {
+	if e.replayEnabled() {
+		// mutex with the replay Subscribe
+		e.historyMu.Lock()
+		defer e.historyMu.Unlock()
+	}
+
+	env = e.newEnvelope(name, publishOptions.Headers, args)
+	var prev interface{}
+	if publishOptions.Retain {
+		prev, _ = e.retained.Swap(name, env)
+	}
+	e.record(env)
+
+	discard = func() {
+		if publishOptions.Retain {
+			// restore the previous retained unless retained again
+			if prev != nil {
+				e.retained.CompareAndSwap(name, env, prev)
+			} else {
+				e.retained.CompareAndDelete(name, env)
 			}
-		}()
+		}
+		e.forget(env)
+	}
+	return env, e.match(name), discard
+}
 
-		defer func() {
+// published counts the accepted publish of event name.
+func (e *Event) published(name string) {
+	atomic.AddUint64(&e.stats.Published, 1)
+	e.getOptions().Metrics.Publish(name)
+}
+
+// match returns the event of name and the wildcard events which matched name.
+func (e *Event) match(name string) []*event {
+	var events []*event
+	if actual, ok := e.list.Load(name); ok {
+		events = append(events, actual.(*event))
+	}
+	for _, pattern := range e.topics.match(name) {
+		if pattern == name {
+			continue
+		}
+		if actual, ok := e.list.Load(pattern); ok {
+			events = append(events, actual.(*event))
+		}
+	}
+	return events
+}
+
+// dispatch args to event callbacks in order, remove Once subscribed and flagged callbacks after done.
+// only the target callback is done when target is not nil.
+// partitioned callbacks are pushed into the partition queue of key when key is not empty.
+func (e *Event) dispatch(ctx context.Context, event *event, target *callback, p *publication, key string, args ...interface{}) {
+	if isDispatching(ctx, event) {
+		// the doneLock is held by the caller of callback, waiting it never ends
+		p.record(fmt.Errorf("%w: %s", ErrRecursivePublish, event.name))
+		return
+	}
+	doneLock, err := e.lock(ctx, event)
+	if err != nil { // publish canceled
+		p.cancel(err)
+		return
+	}
+	if doneLock == nil { // event removed
+		return
+	}
+	defer e.unlock(event, doneLock)
+
+	var env, _ = GetEnvelopeFromContext(ctx)
+
+	event.mu.Lock()
+	var list = event.callbacks
+	event.mu.Unlock()
+
+	for _, cb := range list {
+		// publish canceled
+		if err := ctx.Err(); err != nil {
+			p.cancel(err)
+		}
+		// strict mode or canceled
+		if p.aborted() {
+			return
+		}
+		if target != nil && cb != target {
+			continue
+		}
+		// replay the history first, the replayed publish is skipped
+		if e.replay(event, cb) && cb.subscribeOptions.Once {
+			continue
+		}
+		if env != nil && env.Sequence <= cb.replayed {
+			continue
+		}
+		// skip the expired or exhausted callback, it's removed after done
+		if cb.exhausted(e.now()) {
+			continue
+		}
+		// skip the callback rejected by filter
+		if !e.filter(ctx, cb, args...) {
+			continue
+		}
+		// rate limited callback is deferred or dropped
+		if cb.limiter != nil && env != nil && !cb.limiter.offer(env) {
+			continue
+		}
+		// once subscribe set remove flag
+		if cb.subscribeOptions != nil && cb.subscribeOptions.Once {
 			event.mu.Lock()
-			// mutex with Subscribe
-			event.callbacks = event.callbacks.clearRemoveFlags()
-			if len(event.callbacks) == 0 {
-				close(event.doneLock)
-				event.doneLock = nil
-				e.list.Delete(name)
-			}
+			cb.remove = true
 			event.mu.Unlock()
-		}()
-
-		var errs = make(Errors, 0)
-		<-event.doneLock
-		for i := 0; i < len(event.callbacks); i++ {
-			// once subscribe set remove flag
-			if event.callbacks[i].subscribeOptions != nil && event.callbacks[i].subscribeOptions.Once {
-				event.callbacks[i].remove = true
-			}
-			if event.callbacks[i].f == nil {
-				continue
-			}
-			// exec f
-			err = func() (_err error) {
-				defer func() {
-					if e := recover(); e != nil {
-						switch v := e.(type) {
-						case error:
-							_err = v
-						default:
-							_err = fmt.Errorf("%v", e)
-						}
-					}
-				}()
-				return event.callbacks[i].f(ctx, args...)
-			}()
-			if err != nil {
-				// strict mode
-				if publishOptions.Strict {
+		}
+		if cb.f == nil {
+			continue
+		}
+		// partitioned callback done in partition queue
+		if queue := cb.partition(key); queue != nil {
+			cb := cb
+			ctx := e.detach(ctx)
+			p.add()
+			if _, err := queue.push(e.getOptions().Executor, func() {
+				// publish canceled while waiting in queue
+				if err := ctx.Err(); err != nil {
+					p.cancel(err)
+					p.finish(nil)
 					return
 				}
-				errs = append(errs, err)
+				var err = e.deliver(ctx, cb, args...)
+				e.deadLetter(ctx, cb, err, args...)
+				p.finish(deliveryError(ctx, cb, err))
+			}, nil); err != nil {
+				p.finish(err)
 			}
+			continue
 		}
-		event.doneLock <- struct{}{}
-		err = errs.Nil()
+		// exec f
+		var ctx = withDispatching(ctx, event)
+		var err = e.deliver(ctx, cb, args...)
+		e.deadLetter(ctx, cb, err, args...)
+		p.record(deliveryError(ctx, cb, err))
 	}
+}
 
-	// done
-	go done(ctx, args...)
+// lock the event doneLock, returns nil when event removed and the context error when ctx is done.
+func (e *Event) lock(ctx context.Context, event *event) (chan struct{}, error) {
+	event.mu.Lock()
+	var doneLock = event.doneLock
+	event.mu.Unlock()
+	if doneLock == nil {
+		return nil, nil
+	}
+	select {
+	case _, ok := <-doneLock:
+		if !ok { // event removed
+			return nil, nil
+		}
+		return doneLock, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
 
-	return nil
+type dispatchingCtxKey struct{}
+
+// dispatching is the events which doneLock is held by the caller of callback.
+type dispatching struct {
+	event *event       // event of held doneLock.
+	next  *dispatching // next is the outer dispatching event.
+}
+
+// withDispatching returns the context of callback done with event doneLock held.
+func withDispatching(ctx context.Context, event *event) context.Context {
+	next, _ := ctx.Value(dispatchingCtxKey{}).(*dispatching)
+	return context.WithValue(ctx, dispatchingCtxKey{}, &dispatching{event: event, next: next})
+}
+
+// isDispatching reports whether the event doneLock is held by the caller of callback.
+func isDispatching(ctx context.Context, event *event) bool {
+	for d, _ := ctx.Value(dispatchingCtxKey{}).(*dispatching); d != nil; d = d.next {
+		if d.event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// detach returns the context of publish done by Executor, the held events are cleared unless it's done in the caller goroutine.
+func (e *Event) detach(ctx context.Context) context.Context {
+	if _, ok := e.getOptions().Executor.(InlineExecutor); ok || ctx.Value(dispatchingCtxKey{}) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, dispatchingCtxKey{}, (*dispatching)(nil))
+}
+
+// unlock the event doneLock, remove Once subscribed and flagged callbacks, remove the event when no callback.
+func (e *Event) unlock(event *event, doneLock chan struct{}) {
+	event.mu.Lock()
+	// mutex with Subscribe
+	event.callbacks = event.callbacks.clearRemoveFlags()
+	if len(event.callbacks) == 0 {
+		close(doneLock)
+		event.doneLock = nil
+		e.delete(event.name)
+	} else {
+		doneLock <- struct{}{}
+	}
+	event.mu.Unlock()
+}
+
+// delete event name from list.
+func (e *Event) delete(name string) {
+	e.list.Delete(name)
+	if IsWildcardTopic(name) {
+		// the name subscribed again after deleted is kept
+		e.topics.remove(name, func() bool {
+			_, ok := e.list.Load(name)
+			return ok
+		})
+	}
 }
 
 // Unsubscribe event with callback func list, remove all event when func list is ignore.
+// callback func is matched by func pointer, use Subscription.Unsubscribe to remove a specified closure.
 func (e *Event) Unsubscribe(name string, f ...func(context.Context, ...interface{}) error) {
+	e.unsubscribe(name, matchFuncs(f...))
+}
+
+// matchFuncs returns the match of callbacks which func is one of f, it's nil to match all when f is empty.
+func matchFuncs(f ...func(context.Context, ...interface{}) error) func(*callback) bool {
+	if len(f) == 0 {
+		return nil
+	}
+	return func(cb *callback) bool {
+		for _, item := range f {
+			if sameFunc(cb.f, item) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// remove the callback, it's marked to remove when event in Publish progress.
+func (e *Event) remove(cb *callback) {
+	e.unsubscribe(cb.name, func(item *callback) bool {
+		return item == cb
+	})
+}
+
+// expire start the timer to remove callback when subscription expired, the callback is removed even if not published.
+func (e *Event) expire(event *event, cb *callback) {
+	if cb.subscribeOptions == nil || cb.subscribeOptions.Expiry.IsZero() {
+		return
+	}
+	event.mu.Lock()
+	if cb.active() {
+		cb.expiry = e.getOptions().Clock.AfterFunc(cb.subscribeOptions.Expiry.Sub(e.now()), func() {
+			e.remove(cb)
+		})
+	}
+	event.mu.Unlock()
+}
+
+// unsubscribe event callbacks which matched, remove all event when match is nil.
+func (e *Event) unsubscribe(name string, match func(*callback) bool) {
 	actual, ok := e.list.Load(name)
 	if !ok {
 		return
@@ -150,33 +546,40 @@ func (e *Event) Unsubscribe(name string, f ...func(context.Context, ...interface
 
 	var event = actual.(*event)
 
+	// flush the removed batch subscriptions after unsubscribed
+	var batches []*batcher
+	defer func() {
+		for _, b := range batches {
+			b.flush()
+		}
+	}()
+
 	select {
 	case <-event.doneLock: // not in Publish progress
 		event.mu.Lock()
+		batches = event.callbacks.batches(match)
 		// mutex with Subscribe
-		event.callbacks = event.callbacks.remove(f...)
+		event.callbacks = event.callbacks.removeFunc(match)
 		if len(event.callbacks) == 0 {
 			close(event.doneLock)
 			event.doneLock = nil
-			e.list.Delete(name)
+			e.delete(name)
 		} else {
 			event.doneLock <- struct{}{}
 		}
 		event.mu.Unlock()
 	default:
-		if len(f) == 0 {
-			event.mu.Lock()
-			// mutex with Subscribe
-			event.callbacks = event.callbacks.markRemoveAll()
-			event.mu.Unlock()
-		} else {
-			event.callbacks = event.callbacks.markRemove(f...)
-		}
+		event.mu.Lock()
+		batches = event.callbacks.batches(match)
+		// mutex with Subscribe
+		event.callbacks = event.callbacks.markRemoveFunc(match)
+		event.mu.Unlock()
 	}
 }
 
 // event case.
 type event struct {
+	name      string        // name is the subscribed event name, it can be wildcard.
 	callbacks callbacks     // name callback list
 	mu        sync.Mutex    // mu protects callback list.
 	doneLock  chan struct{} // doneLock has a one-element buffer and is empty when held, it protects at callbacks reduce.
@@ -187,52 +590,241 @@ type callbacks []*callback
 
 // event callback.
 type callback struct {
+	id               uint64 // id is the unique Subscription id.
+	name             string // name is the subscribed event name.
 	f                func(context.Context, ...interface{}) error
-	remove           bool // remove flag for remove when publish.
+	remove           bool           // remove flag for remove when publish.
+	closed           int32          // closed is set to 1 when callback is removed or marked to remove.
+	partitions       []*serialQueue // partitions are the serial queues of partition keys.
+	delivered        uint64         // delivered is the count of successful deliveries.
+	expiry           Timer          // expiry removes the callback when subscription expired, it's protected by event mu.
+	limiter          *limiter       // limiter defers or drops the publish of rate limited callback.
+	batch            *batcher       // batch buffers the publish of batch subscription.
+	responder        bool           // responder is true when callback replies the Request.
+	pending          []*Envelope    // pending history to replay before the live publish.
+	replayed         uint64         // replayed is the last publish sequence covered by history, the live publish not after it is skipped.
 	subscribeOptions *SubscribeOptions
 }
 
-func (list *callbacks) replace(cb *callback) bool {
-	for i := 0; i < len(*list); i++ {
-		if reflect.ValueOf(cb.f).Pointer() == reflect.ValueOf((*list)[i].f).Pointer() {
-			(*list)[i] = cb
-			return true
+// new callback of f with subscribe options.
+func newCallback(id uint64, name string, f func(context.Context, ...interface{}) error, subscribeOptions *SubscribeOptions) *callback {
+	cb := &callback{
+		id:               id,
+		name:             name,
+		f:                f,
+		subscribeOptions: subscribeOptions,
+	}
+	if subscribeOptions != nil && subscribeOptions.Partitions > 0 {
+		cb.partitions = make([]*serialQueue, subscribeOptions.Partitions)
+		for i := range cb.partitions {
+			cb.partitions[i] = new(serialQueue)
 		}
 	}
+	return cb
+}
+
+// deliver args to callback, the failed callback is retried by the subscribed RetryPolicy.
+func (e *Event) deliver(ctx context.Context, cb *callback, args ...interface{}) error {
+	var policy *RetryPolicy
+	if cb.subscribeOptions != nil {
+		policy = cb.subscribeOptions.Retry
+	}
+	var start = e.now()
+	var err = policy.retry(ctx, e.getOptions().Clock, func() error {
+		return e.call(ctx, cb, args...)
+	})
+	e.getOptions().Metrics.Deliver(topic(ctx, cb), cb.name, e.now().Sub(start), err)
+	atomic.AddUint64(&e.stats.Delivered, 1)
+	if err != nil {
+		atomic.AddUint64(&e.stats.Failed, 1)
+		return err
+	}
+	// remove the callback when max deliveries reached
+	if max := cb.maxDeliveries(); max > 0 && atomic.AddUint64(&cb.delivered, 1) >= uint64(max) {
+		e.remove(cb)
+	}
+	return nil
+}
+
+// deliverEnvelope done the Envelope payload to callback out of publish, it must be called with event doneLock held.
+// the failed delivery is put into the DeadLetterSink.
+func (e *Event) deliverEnvelope(ctx context.Context, event *event, cb *callback, env *Envelope) {
+	// once subscribe set remove flag
+	if cb.subscribeOptions != nil && cb.subscribeOptions.Once {
+		event.mu.Lock()
+		cb.remove = true
+		event.mu.Unlock()
+	}
+	ctx = withDispatching(ctx, event)
+	var err = e.deliver(ctx, cb, env.Payload...)
+	e.deadLetter(ctx, cb, err, env.Payload...)
+}
+
+// filter reports whether the callback accepts args by the subscribed filter, the rejected is counted in Stats.
+func (e *Event) filter(ctx context.Context, cb *callback, args ...interface{}) bool {
+	if cb.subscribeOptions == nil || cb.subscribeOptions.Filter == nil {
+		return true
+	}
+	if cb.subscribeOptions.Filter(ctx, args...) {
+		return true
+	}
+	atomic.AddUint64(&e.stats.Filtered, 1)
 	return false
 }
 
-func (list *callbacks) remove(f ...func(context.Context, ...interface{}) error) callbacks {
-	if len(f) == 0 {
-		return (*list)[:0]
+// call callback with args, stop waiting and returns timeout error when the callback timeout exceeded.
+// the callback context is canceled when timeout exceeded.
+func (e *Event) call(ctx context.Context, cb *callback, args ...interface{}) error {
+	var timeout = e.getOptions().Timeout
+	if cb.subscribeOptions != nil && cb.subscribeOptions.Timeout > 0 {
+		timeout = cb.subscribeOptions.Timeout
 	}
-	for i := 0; i < len(*list); i++ {
-		for _, item := range f {
-			if reflect.ValueOf((*list)[i].f).Pointer() == reflect.ValueOf(item).Pointer() {
-				*list = append((*list)[:i], (*list)[i+1:]...)
-				i--
-				break
+	if timeout <= 0 {
+		return e.invoke(ctx, cb, args...)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var result = make(chan error, 1)
+	var panicked = make(chan interface{}, 1)
+	go func() {
+		// re-panic in the publish goroutine
+		defer func() {
+			if v := recover(); v != nil {
+				panicked <- v
 			}
+		}()
+		result <- e.invoke(callCtx, cb, args...)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case v := <-panicked:
+		panic(v)
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil { // publish canceled
+			return err
 		}
+		return fmt.Errorf("%w: subscription %d of %s exceeded %v", ErrCallbackTimeout, cb.id, cb.name, timeout)
 	}
-	return *list
 }
 
-func (list *callbacks) markRemove(f ...func(context.Context, ...interface{}) error) callbacks {
+// invoke callback Handler with args, recover the panic by Event panic policy.
+func (e *Event) invoke(ctx context.Context, cb *callback, args ...interface{}) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = e.recovered(ctx, cb, v)
+		}
+	}()
+	return e.handler(cb)(ctx, args...)
+}
+
+// priority returns the subscribed priority.
+func (cb *callback) priority() int {
+	if cb.subscribeOptions == nil {
+		return 0
+	}
+	return cb.subscribeOptions.Priority
+}
+
+// partition returns the partition queue of key, returns nil when key is empty or callback is not partitioned.
+func (cb *callback) partition(key string) *serialQueue {
+	if key == "" || len(cb.partitions) == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return cb.partitions[h.Sum32()%uint32(len(cb.partitions))]
+}
+
+// close set the callback inactive and stop the timers, it's called with event mu held.
+func (cb *callback) close() {
+	atomic.StoreInt32(&cb.closed, 1)
+	if cb.expiry != nil {
+		cb.expiry.Stop()
+	}
+	if cb.limiter != nil {
+		cb.limiter.stop()
+	}
+	if cb.batch != nil {
+		cb.batch.close()
+	}
+}
+
+// maxDeliveries returns the subscribed max count of successful deliveries, zero is unlimited.
+func (cb *callback) maxDeliveries() int {
+	if cb.subscribeOptions == nil {
+		return 0
+	}
+	return cb.subscribeOptions.MaxDeliveries
+}
+
+// exhausted reports whether the callback reached max deliveries or expired at now.
+func (cb *callback) exhausted(now time.Time) bool {
+	if max := cb.maxDeliveries(); max > 0 && atomic.LoadUint64(&cb.delivered) >= uint64(max) {
+		return true
+	}
+	if cb.subscribeOptions == nil || cb.subscribeOptions.Expiry.IsZero() {
+		return false
+	}
+	return !now.Before(cb.subscribeOptions.Expiry)
+}
+
+// active reports whether callback is still subscribed.
+func (cb *callback) active() bool {
+	return atomic.LoadInt32(&cb.closed) == 0
+}
+
+// sameFunc reports whether a and b have the same func pointer.
+func sameFunc(a, b func(context.Context, ...interface{}) error) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// insert callback before the first lower priority callback, keep the subscribed order of equal priority.
+// returns a new list, the list in Publish progress is not changed.
+func (list *callbacks) insert(cb *callback) callbacks {
+	var idx = sort.Search(len(*list), func(i int) bool {
+		return (*list)[i].priority() < cb.priority()
+	})
+	var result = make(callbacks, 0, len(*list)+1)
+	result = append(result, (*list)[:idx]...)
+	result = append(result, cb)
+	result = append(result, (*list)[idx:]...)
+	return result
+}
+
+// removeFunc remove callbacks which matched, remove all when match is nil.
+func (list *callbacks) removeFunc(match func(*callback) bool) callbacks {
 	for i := 0; i < len(*list); i++ {
-		for _, item := range f {
-			if reflect.ValueOf((*list)[i].f).Pointer() == reflect.ValueOf(item).Pointer() {
-				(*list)[i].remove = true
-				break
-			}
+		if match == nil || match((*list)[i]) {
+			(*list)[i].close()
+			*list = append((*list)[:i], (*list)[i+1:]...)
+			i--
 		}
 	}
 	return *list
 }
 
-func (list *callbacks) markRemoveAll() callbacks {
+// batches returns the batchers of batch subscriptions which matched, all when match is nil.
+func (list *callbacks) batches(match func(*callback) bool) []*batcher {
+	var result []*batcher
+	for _, cb := range *list {
+		if cb.batch != nil && (match == nil || match(cb)) {
+			result = append(result, cb.batch)
+		}
+	}
+	return result
+}
+
+// markRemoveFunc set remove flag of callbacks which matched, mark all when match is nil.
+func (list *callbacks) markRemoveFunc(match func(*callback) bool) callbacks {
 	for i := 0; i < len(*list); i++ {
-		(*list)[i].remove = true
+		if match == nil || match((*list)[i]) {
+			(*list)[i].remove = true
+			(*list)[i].close()
+		}
 	}
 	return *list
 }
@@ -240,6 +832,7 @@ func (list *callbacks) markRemoveAll() callbacks {
 func (list *callbacks) clearRemoveFlags() callbacks {
 	for i := 0; i < len(*list); i++ {
 		if (*list)[i].remove {
+			(*list)[i].close()
 			*list = append((*list)[:i], (*list)[i+1:]...)
 			i--
 		}
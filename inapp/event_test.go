@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -90,47 +91,6 @@ var (
 	}
 )
 
-func Test_callbacks_replace(t *testing.T) {
-	type args struct {
-		cb *callback
-	}
-	tests := []struct {
-		name string
-		list callbacks
-		args args
-		want bool
-	}{
-		{
-			name: "exist",
-			list: callbacks{cb1, cb2, cb3, cb4},
-			args: args{
-				cb: &callback{
-					f: f1,
-					subscribeOptions: &SubscribeOptions{
-						Once: true,
-					},
-				},
-			},
-			want: true,
-		},
-		{
-			name: "not exist",
-			list: callbacks{cb1, cb2, cb3},
-			args: args{
-				cb: cb4,
-			},
-			want: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.list.replace(tt.args.cb); got != tt.want {
-				t.Errorf("replace() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func Test_callbacks_remove(t *testing.T) {
 	type args struct {
 		f []func(context.Context, ...interface{}) error
@@ -142,10 +102,9 @@ func Test_callbacks_remove(t *testing.T) {
 		want_list callbacks
 	}{
 		{
-			name: "remove nil",
-			list: callbacks{cb1, cb2, cb3, cb4},
-			args: args{
-			},
+			name:      "remove nil",
+			list:      callbacks{cb1, cb2, cb3, cb4},
+			args:      args{},
 			want_list: make(callbacks, 0),
 		},
 		{
@@ -221,8 +180,59 @@ func Test_callbacks_remove(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got_list := tt.list.remove(tt.args.f...); !reflect.DeepEqual(got_list, tt.want_list) {
-				t.Errorf("remove() = %v, want %v", got_list, tt.want_list)
+			if got_list := tt.list.removeFunc(matchFuncs(tt.args.f...)); !reflect.DeepEqual(got_list, tt.want_list) {
+				t.Errorf("removeFunc() = %v, want %v", got_list, tt.want_list)
+			}
+		})
+	}
+}
+
+func Test_callbacks_insert(t *testing.T) {
+	var (
+		p0  = &callback{f: f1}
+		p1  = &callback{f: f2, subscribeOptions: &SubscribeOptions{Priority: 1}}
+		p1b = &callback{f: f3, subscribeOptions: &SubscribeOptions{Priority: 1}}
+		pn  = &callback{f: f4, subscribeOptions: &SubscribeOptions{Priority: -1}}
+	)
+	tests := []struct {
+		name string
+		list callbacks
+		cb   *callback
+		want callbacks
+	}{
+		{
+			name: "empty",
+			list: callbacks{},
+			cb:   p0,
+			want: callbacks{p0},
+		},
+		{
+			name: "higher first",
+			list: callbacks{p0, pn},
+			cb:   p1,
+			want: callbacks{p1, p0, pn},
+		},
+		{
+			name: "equal after",
+			list: callbacks{p1, p0},
+			cb:   p1b,
+			want: callbacks{p1, p1b, p0},
+		},
+		{
+			name: "lower last",
+			list: callbacks{p1, p0},
+			cb:   pn,
+			want: callbacks{p1, p0, pn},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var origin = append(callbacks{}, tt.list...)
+			if got := tt.list.insert(tt.cb); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("insert() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.list, origin) {
+				t.Errorf("insert() changed list %v, want %v", tt.list, origin)
 			}
 		})
 	}
@@ -279,7 +289,7 @@ func Test_callbacks_markRemove(t *testing.T) {
 			},
 		},
 		{
-			name: "empty",
+			name: "empty marks all",
 			list: callbacks{
 				&callback{
 					f:                f1,
@@ -312,8 +322,8 @@ func Test_callbacks_markRemove(t *testing.T) {
 					return fmt.Errorf("want length 4, got %d", len(list))
 				}
 				for idx, item := range list {
-					if item.remove {
-						return fmt.Errorf("want remove false, got %t @%d", item.remove, idx)
+					if !item.remove {
+						return fmt.Errorf("want remove true, got %t @%d", item.remove, idx)
 					}
 				}
 				return nil
@@ -357,9 +367,9 @@ func Test_callbacks_markRemove(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.list.markRemove(tt.args.f...)
+			got := tt.list.markRemoveFunc(matchFuncs(tt.args.f...))
 			if err := tt.result(got); err != nil {
-				t.Errorf("markRemove() error %v", err)
+				t.Errorf("markRemoveFunc() error %v", err)
 			}
 		})
 	}
@@ -449,8 +459,9 @@ func TestEvent_Subscribe(t *testing.T) {
 	}
 
 	e := &Event{}
+	var subs = make([]*Subscription, 0, len(tests))
 	for _, tt := range tests {
-		e.Subscribe(tt.args.ctx, tt.args.name, tt.args.f)
+		subs = append(subs, e.Subscribe(tt.args.ctx, tt.args.name, tt.args.f))
 	}
 
 	// test event
@@ -462,22 +473,22 @@ func TestEvent_Subscribe(t *testing.T) {
 	if !ok {
 		t.Fatalf("should be callbacks type")
 	}
-	if len(eventCase.callbacks) != 3 {
-		t.Fatalf("want test length 3, got %d", len(eventCase.callbacks))
+	if len(eventCase.callbacks) != len(tests) {
+		t.Fatalf("want test length %d, got %d", len(tests), len(eventCase.callbacks))
 	}
-	for _, item := range eventCase.callbacks {
-		if reflect.ValueOf(item.f).Pointer() == reflect.ValueOf(f1).Pointer() {
-			if item.subscribeOptions.Once != true {
-				t.Fatalf("want once true, got %t", item.subscribeOptions.Once)
-			}
-		} else if reflect.ValueOf(item.f).Pointer() == reflect.ValueOf(f2).Pointer() {
-			if item.subscribeOptions != nil {
-				t.Fatalf("want subscribeOptions nil, got %v", item.subscribeOptions)
-			}
-		} else if reflect.ValueOf(item.f).Pointer() == reflect.ValueOf(f3).Pointer() {
-			if item.subscribeOptions != nil {
-				t.Fatalf("want subscribeOptions nil, got %v", item.subscribeOptions)
+	for idx, item := range eventCase.callbacks {
+		if item.id != subs[idx].ID() {
+			t.Fatalf("want id %d, got %d @%d", subs[idx].ID(), item.id, idx)
+		}
+		if !sameFunc(item.f, tests[idx].args.f) {
+			t.Fatalf("want func %s @%d", tests[idx].name, idx)
+		}
+		if _, ok := GetSubscribeOptionFromContext(tests[idx].args.ctx); ok {
+			if item.subscribeOptions == nil || !item.subscribeOptions.Once {
+				t.Fatalf("want once true, got %v @%d", item.subscribeOptions, idx)
 			}
+		} else if item.subscribeOptions != nil {
+			t.Fatalf("want subscribeOptions nil, got %v @%d", item.subscribeOptions, idx)
 		}
 	}
 }
@@ -568,17 +579,8 @@ func TestEvent_Publish(t *testing.T) {
 				defer timer.Stop()
 				select {
 				case err := <-errCh:
-					switch e := err.(type) {
-					case Errors:
-						for _, item := range e {
-							if item == ErrTest {
-								return nil
-							}
-						}
-					case error:
-						if e == ErrTest {
-							return nil
-						}
+					if errors.Is(err, ErrTest) {
+						return nil
 					}
 					return err
 				case <-timer.C:
@@ -631,7 +633,7 @@ func TestEvent_Publish(t *testing.T) {
 				defer timer.Stop()
 				select {
 				case err := <-errCh:
-					if err == ErrTest {
+					if errors.Is(err, ErrStrictAborted) && errors.Is(err, ErrTest) {
 						return nil
 					}
 					return err
@@ -660,7 +662,7 @@ func TestEvent_Publish(t *testing.T) {
 				defer timer.Stop()
 				select {
 				case err := <-errCh:
-					if err == ErrPanic {
+					if list, ok := err.(Errors); ok && len(list) == 1 && errors.Is(list[0], ErrPanic) && errors.Is(list[0], ErrCallbackPanic) {
 						return nil
 					}
 					return err
@@ -731,6 +733,7 @@ func TestEvent_Publish(t *testing.T) {
 				args: []interface{}{&count},
 			},
 			init: func(e *Event, args *args) {
+				i8 = 1
 				e.Subscribe(NewSubscribeOptionContext(args.ctx, WithOnceOption(true)), args.name, fData)
 			},
 			result: func(e *Event) error {
@@ -743,8 +746,6 @@ func TestEvent_Publish(t *testing.T) {
 					}
 					if _, ok := e.list.Load("test"); ok {
 						return ErrUnexpected
-					} else {
-						return nil
 					}
 					if err == nil {
 						return nil
@@ -980,6 +981,515 @@ func TestEvent_Unsubscribe(t *testing.T) {
 	}
 }
 
+func TestEvent_PublishSync(t *testing.T) {
+	type args struct {
+		ctx  context.Context
+		name string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		init    func(*Event, *args)
+		result  func(*Event, error, int) error
+		wantErr bool
+	}{
+		{
+			name: "normal",
+			args: args{
+				ctx:  context.TODO(),
+				name: "test",
+			},
+			init: func(e *Event, args *args) {
+				e.Subscribe(args.ctx, args.name, f1)
+				e.Subscribe(args.ctx, args.name, f2)
+			},
+			result: func(e *Event, err error, count int) error {
+				if count != 2 {
+					return fmt.Errorf("want count 2, got %d", count)
+				}
+				return nil
+			},
+		},
+		{
+			name: "not exist",
+			args: args{
+				ctx:  context.TODO(),
+				name: "test",
+			},
+			result: func(e *Event, err error, count int) error {
+				if err != ErrNotExistEvent {
+					return fmt.Errorf("want error %v, got %v", ErrNotExistEvent, err)
+				}
+				return nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "return errors",
+			args: args{
+				ctx:  context.TODO(),
+				name: "test",
+			},
+			init: func(e *Event, args *args) {
+				e.Subscribe(args.ctx, args.name, fError)
+				e.Subscribe(args.ctx, args.name, fPanic)
+				e.Subscribe(args.ctx, args.name, f1)
+			},
+			result: func(e *Event, err error, count int) error {
+				list, ok := err.(Errors)
+				if !ok || len(list) != 2 || !errors.Is(list[0], ErrTest) || !errors.Is(list[1], ErrPanic) || !errors.Is(list[1], ErrCallbackPanic) {
+					return fmt.Errorf("want errors [%v,%v], got %v", ErrTest, ErrPanic, err)
+				}
+				var deliveryErr *DeliveryError
+				if !errors.As(list[1], &deliveryErr) || deliveryErr.Event != "test" || deliveryErr.Subscription != 2 || deliveryErr.Attempt != 1 {
+					return fmt.Errorf("want delivery error of subscription 2, got %v", list[1])
+				}
+				if count != 1 {
+					return fmt.Errorf("want count 1, got %d", count)
+				}
+				return nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "return error in strict mode",
+			args: args{
+				ctx:  NewPublishOptionContext(context.TODO(), WithStrictModeOption(true)),
+				name: "test",
+			},
+			init: func(e *Event, args *args) {
+				e.Subscribe(args.ctx, args.name, f1)
+				e.Subscribe(args.ctx, args.name, fError)
+				e.Subscribe(args.ctx, args.name, f2)
+			},
+			result: func(e *Event, err error, count int) error {
+				if !errors.Is(err, ErrStrictAborted) || !errors.Is(err, ErrTest) {
+					return fmt.Errorf("want error %v, got %v", ErrTest, err)
+				}
+				if count != 1 {
+					return fmt.Errorf("want count 1, got %d", count)
+				}
+				// the event is still available after strict mode interrupted.
+				if err := e.PublishSync(NewPublishOptionContext(context.TODO(), WithStrictModeOption(true)), "test", &count); !errors.Is(err, ErrTest) {
+					return fmt.Errorf("want error %v, got %v", ErrTest, err)
+				}
+				return nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "once",
+			args: args{
+				ctx:  context.TODO(),
+				name: "test",
+			},
+			init: func(e *Event, args *args) {
+				e.Subscribe(NewSubscribeOptionContext(args.ctx, WithOnceOption(true)), args.name, f1)
+			},
+			result: func(e *Event, err error, count int) error {
+				if count != 1 {
+					return fmt.Errorf("want count 1, got %d", count)
+				}
+				if _, ok := e.list.Load("test"); ok {
+					return fmt.Errorf("should be remove the test event")
+				}
+				return nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int
+			e := NewEvent()
+			if tt.init != nil {
+				tt.init(e, &tt.args)
+			}
+			err := e.PublishSync(tt.args.ctx, tt.args.name, &count)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PublishSync() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.result != nil {
+				if err := tt.result(e, err, count); err != nil {
+					t.Errorf("PublishSync() error %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestEvent_PublishOrdered(t *testing.T) {
+	const (
+		publishers = 8
+		count      = 200
+	)
+
+	type message struct {
+		publisher int
+		seq       int
+	}
+
+	var (
+		errs     = make(chan error, publishers*count)
+		received = make(chan struct{}, publishers*count)
+		last     = make([]int, publishers)
+	)
+
+	fOrder := func(ctx context.Context, args ...interface{}) error {
+		msg := args[0].(message)
+		// callbacks of the same name are done one by one, last is not shared.
+		if msg.seq != last[msg.publisher]+1 {
+			errs <- fmt.Errorf("publisher %d want seq %d, got %d", msg.publisher, last[msg.publisher]+1, msg.seq)
+		}
+		last[msg.publisher] = msg.seq
+		received <- struct{}{}
+		return nil
+	}
+
+	e := NewEvent(WithOrdered(true))
+	e.Subscribe(context.TODO(), "test", fOrder)
+
+	for p := 0; p < publishers; p++ {
+		go func(p int) {
+			for seq := 1; seq <= count; seq++ {
+				if err := e.Publish(context.TODO(), "test", message{publisher: p, seq: seq}); err != nil {
+					errs <- err
+				}
+			}
+		}(p)
+	}
+
+	timer := time.NewTimer(time.Second * 10)
+	defer timer.Stop()
+	for i := 0; i < publishers*count; i++ {
+		select {
+		case err := <-errs:
+			t.Fatalf("Publish() error %v", err)
+		case <-received:
+		case <-timer.C:
+			t.Fatalf("want %d deliveries, got %d", publishers*count, i)
+		}
+	}
+}
+
+func TestEvent_PublishOrderedConcurrentNames(t *testing.T) {
+	var (
+		errCh = make(chan error, 1)
+		block = make(chan struct{})
+	)
+	defer close(block)
+
+	fBlock := func(ctx context.Context, args ...interface{}) error {
+		<-block
+		return nil
+	}
+
+	e := NewEvent(WithOrdered(true))
+	e.Subscribe(context.TODO(), "slow", fBlock)
+	e.Subscribe(context.TODO(), "fast", f1)
+
+	if err := e.Publish(context.TODO(), "slow"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := e.Publish(NewPublishOptionContext(context.TODO(), WithErrorOption(errCh)), "fast"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	case <-time.After(time.Second * 3):
+		t.Fatalf("fast event should not wait the slow event")
+	}
+}
+
+func TestEvent_PublishPartition(t *testing.T) {
+	const (
+		keys  = 16
+		count = 100
+	)
+
+	var (
+		mu       sync.Mutex
+		last     = make(map[string]int)
+		errs     = make(chan error, keys*count)
+		received = make(chan struct{}, keys*count)
+	)
+
+	fOrder := func(ctx context.Context, args ...interface{}) error {
+		key, seq := args[0].(string), args[1].(int)
+		mu.Lock()
+		if seq != last[key]+1 {
+			errs <- fmt.Errorf("key %s want seq %d, got %d", key, last[key]+1, seq)
+		}
+		last[key] = seq
+		mu.Unlock()
+		received <- struct{}{}
+		return nil
+	}
+
+	e := NewEvent()
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithPartitionsOption(4)), "test", fOrder)
+
+	for k := 0; k < keys; k++ {
+		go func(key string) {
+			for seq := 1; seq <= count; seq++ {
+				ctx := NewPublishOptionContext(context.TODO(), WithPartitionKeyOption(key))
+				if err := e.Publish(ctx, "test", key, seq); err != nil {
+					errs <- err
+				}
+			}
+		}("key" + strconv.Itoa(k))
+	}
+
+	timer := time.NewTimer(time.Second * 10)
+	defer timer.Stop()
+	for i := 0; i < keys*count; i++ {
+		select {
+		case err := <-errs:
+			t.Fatalf("Publish() error %v", err)
+		case <-received:
+		case <-timer.C:
+			t.Fatalf("want %d deliveries, got %d", keys*count, i)
+		}
+	}
+}
+
+func TestEvent_PublishPartitionConcurrent(t *testing.T) {
+	var (
+		errCh = make(chan error, 2)
+		block = make(chan struct{})
+	)
+
+	// find two keys in different partitions.
+	var (
+		cb   = newCallback(0, "test", f1, &SubscribeOptions{Partitions: 2})
+		slow = "key0"
+		fast string
+	)
+	for i := 1; fast == ""; i++ {
+		if key := "key" + strconv.Itoa(i); cb.partition(key) != cb.partition(slow) {
+			fast = key
+		}
+	}
+
+	fBlock := func(ctx context.Context, args ...interface{}) error {
+		if args[0] == slow {
+			<-block
+		}
+		if args[0] == fast {
+			return ErrTest
+		}
+		return nil
+	}
+
+	e := NewEvent()
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithPartitionsOption(2)), "test", fBlock)
+
+	for _, key := range []string{slow, fast} {
+		ctx := NewPublishOptionContext(context.TODO(), WithPartitionKeyOption(key), WithErrorOption(errCh))
+		if err := e.Publish(ctx, "test", key); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	select {
+	case err := <-errCh:
+		if list, ok := err.(Errors); !ok || len(list) != 1 || !errors.Is(list[0], ErrTest) {
+			t.Fatalf("want errors [%v], got %v", ErrTest, err)
+		}
+	case <-time.After(time.Second * 3):
+		t.Fatalf("key %s should not wait the key %s", fast, slow)
+	}
+
+	close(block)
+	if err := <-errCh; err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+}
+
+func TestEvent_PublishPriority(t *testing.T) {
+	var order []string
+
+	newCallback := func(name string, err error) func(context.Context, ...interface{}) error {
+		return func(ctx context.Context, args ...interface{}) error {
+			order = append(order, name)
+			return err
+		}
+	}
+
+	e := NewEvent()
+	e.Subscribe(context.TODO(), "test", newCallback("persist", nil))
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithPriorityOption(10)), "test", newCallback("validate", ErrTest))
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithPriorityOption(-10)), "test", newCallback("notify", nil))
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithPriorityOption(10)), "test", newCallback("authorize", nil))
+
+	if err := e.PublishSync(context.TODO(), "test"); err == nil {
+		t.Fatalf("want error %v, got nil", ErrTest)
+	}
+	if want := []string{"validate", "authorize", "persist", "notify"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("want order %v, got %v", want, order)
+	}
+
+	order = nil
+	if err := e.PublishSync(NewPublishOptionContext(context.TODO(), WithStrictModeOption(true)), "test"); !errors.Is(err, ErrStrictAborted) || !errors.Is(err, ErrTest) {
+		t.Fatalf("want error %v, got %v", ErrTest, err)
+	}
+	if want := []string{"validate"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("want order %v, got %v", want, order)
+	}
+}
+
+func TestEvent_PublishContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	e := NewEvent()
+	e.Subscribe(context.TODO(), "test", f1)
+
+	// fail fast
+	if err := e.Publish(canceled, "test"); err != context.Canceled {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	if err := e.PublishSync(canceled, "test"); err != context.Canceled {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+
+	// stop waiting the busy event
+	value, _ := e.list.Load("test")
+	var doneLock = value.(*event).doneLock
+	<-doneLock
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Millisecond*10)
+	defer cancel()
+	err := e.PublishSync(ctx, "test")
+	if list, ok := err.(Errors); !ok || len(list) != 1 || !errors.Is(list[0], ErrPublishCanceled) || !errors.Is(list[0], context.DeadlineExceeded) {
+		t.Fatalf("want error %v, got %v", context.DeadlineExceeded, err)
+	}
+	doneLock <- struct{}{}
+
+	// skip remaining callbacks
+	var count int
+	ctx, cancel = context.WithCancel(context.TODO())
+	defer cancel()
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithPriorityOption(1)), "test", func(ctx context.Context, args ...interface{}) error {
+		cancel()
+		return nil
+	})
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithOnceOption(true)), "test", f2)
+	if err := e.PublishSync(NewPublishOptionContext(ctx, WithStrictModeOption(true)), "test", &count); !errors.Is(err, ErrPublishCanceled) || !errors.Is(err, context.Canceled) || errors.Is(err, ErrStrictAborted) {
+		t.Fatalf("want error %v, got %v", context.Canceled, err)
+	}
+	if count != 0 {
+		t.Fatalf("want count 0, got %d", count)
+	}
+	// the skipped once callback is still subscribed.
+	if err := e.PublishSync(context.TODO(), "test", &count); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("want count 2, got %d", count)
+	}
+}
+
+func TestEvent_PublishTimeout(t *testing.T) {
+	var (
+		block    = make(chan struct{})
+		canceled = make(chan error, 2)
+	)
+	defer close(block)
+
+	fHang := func(ctx context.Context, args ...interface{}) error {
+		select {
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+		case <-block:
+		}
+		<-block
+		return nil
+	}
+
+	tests := []struct {
+		name string
+		e    *Event
+		opts []SubscribeOption
+	}{
+		{
+			name: "subscribe timeout",
+			e:    NewEvent(),
+			opts: []SubscribeOption{WithPriorityOption(1), WithTimeoutOption(time.Millisecond * 20)},
+		},
+		{
+			name: "default timeout",
+			e:    NewEvent(WithDefaultTimeout(time.Millisecond * 20)),
+			opts: []SubscribeOption{WithPriorityOption(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int
+			tt.e.Subscribe(NewSubscribeOptionContext(context.TODO(), tt.opts...), "test", fHang)
+			tt.e.Subscribe(context.TODO(), "test", f1)
+
+			for i := 1; i <= 2; i++ {
+				err := tt.e.PublishSync(context.TODO(), "test", &count)
+				if list, ok := err.(Errors); !ok || len(list) != 1 || !errors.Is(list[0], ErrCallbackTimeout) {
+					t.Fatalf("want error %v, got %v", ErrCallbackTimeout, err)
+				}
+				if count != i {
+					t.Fatalf("want count %d, got %d", i, count)
+				}
+				if err := <-canceled; err != context.DeadlineExceeded {
+					t.Fatalf("want callback context error %v, got %v", context.DeadlineExceeded, err)
+				}
+			}
+		})
+	}
+}
+
+func TestEvent_SubscribeFilter(t *testing.T) {
+	even := func(ctx context.Context, args ...interface{}) bool {
+		return args[0].(int)%2 == 0
+	}
+
+	tests := []struct {
+		name string
+		opts []SubscribeOption
+		want []int
+	}{
+		{
+			name: "no filter",
+			want: []int{1, 2, 3, 4},
+		},
+		{
+			name: "filter",
+			opts: []SubscribeOption{WithFilterOption(even)},
+			want: []int{2, 4},
+		},
+		{
+			name: "filter once",
+			opts: []SubscribeOption{WithFilterOption(even), WithOnceOption(true)},
+			want: []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e = NewEvent()
+			var got []int
+			e.Subscribe(NewSubscribeOptionContext(context.TODO(), tt.opts...), "test", func(ctx context.Context, args ...interface{}) error {
+				got = append(got, args[0].(int))
+				return nil
+			})
+			for i := 1; i <= 4; i++ {
+				e.PublishSync(context.TODO(), "test", i)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestEventMutex(t *testing.T) {
 	var (
 		name = "test"
@@ -1123,3 +1633,78 @@ func TestEventMutex(t *testing.T) {
 		return
 	}
 }
+
+func TestEvent_PublishRecursive(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []EventOption
+		publish func(e *Event, ctx context.Context) error // publish in callback
+		wantErr error
+	}{
+		{
+			name: "sync",
+			publish: func(e *Event, ctx context.Context) error {
+				return e.PublishSync(ctx, "order.paid")
+			},
+			wantErr: ErrRecursivePublish,
+		},
+		{
+			name:    "inline executor",
+			options: []EventOption{WithExecutor(InlineExecutor{})},
+			publish: func(e *Event, ctx context.Context) error {
+				var result = make(chan error, 1)
+				if err := e.Publish(NewPublishOptionContext(ctx, WithErrorOption(result)), "order.paid"); err != nil {
+					return err
+				}
+				return <-result
+			},
+			wantErr: ErrRecursivePublish,
+		},
+		{
+			name: "request",
+			publish: func(e *Event, ctx context.Context) error {
+				_, err := e.Request(ctx, "order.paid")
+				return err
+			},
+			wantErr: ErrRecursivePublish,
+		},
+		{
+			name: "async",
+			publish: func(e *Event, ctx context.Context) error {
+				return e.Publish(ctx, "order.paid")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e = NewEvent(tt.options...)
+			var paid = make(chan struct{}, 1)
+			var result = make(chan error, 1)
+			e.Respond(context.TODO(), "order.*", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+				if topic, _ := GetTopicFromContext(ctx); topic == "order.paid" {
+					paid <- struct{}{}
+					return nil, nil
+				}
+				result <- tt.publish(e, ctx)
+				return nil, nil
+			})
+
+			go e.PublishSync(context.TODO(), "order.created")
+			select {
+			case err := <-result:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want error %v, got %v", tt.wantErr, err)
+				}
+			case <-time.After(time.Second * 2):
+				t.Fatalf("publish in callback is blocked")
+			}
+			if tt.wantErr == nil {
+				select {
+				case <-paid:
+				case <-time.After(time.Second):
+					t.Fatalf("want nested publish done")
+				}
+			}
+		})
+	}
+}
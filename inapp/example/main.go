@@ -13,7 +13,7 @@ func main() {
 
 	// callback
 	var f1 = func(ctx context.Context, args ...interface{}) error {
-		fmt.Sprintf("got args %v\n", args)
+		fmt.Printf("got args %v\n", args)
 		return nil
 	}
 
@@ -22,7 +22,7 @@ func main() {
 
 	// Subscribe test event with Once option
 	event.Subscribe(inapp.NewSubscribeOptionContext(context.TODO(), inapp.WithOnceOption(true)), "test", func(ctx context.Context, args ...interface{}) error {
-		fmt.Sprintf("got args %v\n", args)
+		fmt.Printf("got args %v\n", args)
 		return nil
 	})
 
@@ -0,0 +1,172 @@
+package inapp
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrQueueFull      = errors.New("executor queue full")
+	ErrExecutorClosed = errors.New("executor closed")
+)
+
+// Executor executes the async publish task.
+type Executor interface {
+	// Execute task, returns error when task can't be accepted.
+	Execute(task func()) error
+}
+
+// GoExecutor executes every task in a new goroutine, it's the default Executor.
+type GoExecutor struct{}
+
+// Execute task in a new goroutine.
+func (GoExecutor) Execute(task func()) error {
+	go task()
+	return nil
+}
+
+// InlineExecutor executes task in the caller goroutine, Publish will wait the callbacks done.
+// the publish option Err chan should be buffered or received in other goroutine.
+type InlineExecutor struct{}
+
+// Execute task in the caller goroutine.
+func (InlineExecutor) Execute(task func()) error {
+	task()
+	return nil
+}
+
+// PoolExecutor executes task by a fixed size worker pool with a bounded queue.
+// the task panic is recovered to keep worker alive, so the PanicRepanic policy only reports the PanicError by publish.
+type PoolExecutor struct {
+	queue  chan func()    // queue of waiting tasks.
+	mu     sync.RWMutex   // mu protects closed.
+	closed bool           // closed is true when Close called.
+	wg     sync.WaitGroup // wg waits workers exit.
+}
+
+// New PoolExecutor with workers count and queue size, workers is at least 1.
+func NewPoolExecutor(workers, size int) *PoolExecutor {
+	if workers < 1 {
+		workers = 1
+	}
+	if size < 0 {
+		size = 0
+	}
+	p := &PoolExecutor{
+		queue: make(chan func(), size),
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Execute put task into queue, returns ErrQueueFull when queue is full and all workers are busy.
+func (p *PoolExecutor) Execute(task func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrExecutorClosed
+	}
+	select {
+	case p.queue <- task:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close the pool, the queued tasks will be done before workers exit.
+func (p *PoolExecutor) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *PoolExecutor) work() {
+	defer p.wg.Done()
+
+	for task := range p.queue {
+		func() {
+			// keep worker alive when task panic.
+			defer func() {
+				recover()
+			}()
+			task()
+		}()
+	}
+}
+
+// serial queue done tasks one by one in push order by Executor.
+type serialQueue struct {
+	mu      sync.Mutex // mu protects fields.
+	tasks   []func()   // waiting tasks.
+	running bool       // running is true when the drain is executing.
+	dead    bool       // dead is true when the drained queue is removed, it can't be pushed any more.
+}
+
+// push task into queue, executes the drain when not running, returns false when queue is dead.
+func (q *serialQueue) push(executor Executor, task func(), drained func()) (bool, error) {
+	q.mu.Lock()
+	if q.dead {
+		q.mu.Unlock()
+		return false, nil
+	}
+	q.tasks = append(q.tasks, task)
+	if q.running {
+		q.mu.Unlock()
+		return true, nil
+	}
+	q.running = true
+	q.mu.Unlock()
+
+	if err := executor.Execute(func() { q.drain(drained) }); err != nil {
+		// the first task is pushed by self, the others pushed meanwhile are accepted,
+		// so drain them in the caller goroutine.
+		q.mu.Lock()
+		q.tasks[0] = nil
+		q.tasks = q.tasks[1:]
+		q.mu.Unlock()
+		q.drain(drained)
+		return true, err
+	}
+	return true, nil
+}
+
+// drain done tasks until queue is empty, then mark queue dead and call drained when drained is not nil.
+// the remaining tasks are drained in a new goroutine when task panic.
+func (q *serialQueue) drain(drained func()) {
+	var done bool
+	defer func() {
+		if !done {
+			go q.drain(drained)
+		}
+	}()
+
+	for {
+		q.mu.Lock()
+		if len(q.tasks) == 0 {
+			q.running = false
+			q.dead = drained != nil
+			q.mu.Unlock()
+			done = true
+			if drained != nil {
+				drained()
+			}
+			return
+		}
+		task := q.tasks[0]
+		q.tasks[0] = nil
+		q.tasks = q.tasks[1:]
+		q.mu.Unlock()
+
+		task()
+	}
+}
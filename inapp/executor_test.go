@@ -0,0 +1,154 @@
+package inapp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolExecutor_Execute(t *testing.T) {
+	var (
+		count   int32
+		started = make(chan struct{})
+		block   = make(chan struct{})
+	)
+
+	p := NewPoolExecutor(1, 1)
+
+	// worker busy
+	if err := p.Execute(func() {
+		close(started)
+		<-block
+		atomic.AddInt32(&count, 1)
+	}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	<-started
+	// queued
+	if err := p.Execute(func() {
+		atomic.AddInt32(&count, 1)
+	}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	// full
+	if err := p.Execute(func() {}); err != ErrQueueFull {
+		t.Fatalf("want error %v, got %v", ErrQueueFull, err)
+	}
+
+	close(block)
+	p.Close()
+
+	if count != 2 {
+		t.Fatalf("want count 2, got %d", count)
+	}
+	if err := p.Execute(func() {}); err != ErrExecutorClosed {
+		t.Fatalf("want error %v, got %v", ErrExecutorClosed, err)
+	}
+}
+
+func TestPoolExecutor_Panic(t *testing.T) {
+	var done = make(chan struct{})
+
+	p := NewPoolExecutor(1, 2)
+	defer p.Close()
+
+	p.Execute(func() {
+		panic(ErrPanic)
+	})
+	p.Execute(func() {
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("worker should be alive after task panic")
+	}
+}
+
+func TestEvent_PublishExecutor(t *testing.T) {
+	var block = make(chan struct{})
+
+	fBlock := func(ctx context.Context, args ...interface{}) error {
+		<-block
+		return nil
+	}
+
+	p := NewPoolExecutor(1, 0)
+	e := NewEvent(WithExecutor(p))
+	e.Subscribe(context.TODO(), "test", fBlock)
+
+	// wait the worker idle
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = e.Publish(context.TODO(), "test"); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := e.Publish(context.TODO(), "test"); err != ErrQueueFull {
+		t.Fatalf("want error %v, got %v", ErrQueueFull, err)
+	}
+
+	close(block)
+	p.Close()
+
+	// inline executor done callbacks before Publish returns.
+	var count int
+	e = NewEvent(WithExecutor(InlineExecutor{}))
+	e.Subscribe(context.TODO(), "test", f1)
+	if err := e.Publish(context.TODO(), "test", &count); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("want count 1, got %d", count)
+	}
+}
+
+// rejectExecutor rejects every task, before returns it calls pushed.
+type rejectExecutor struct {
+	pushed func()
+}
+
+func (r rejectExecutor) Execute(task func()) error {
+	if r.pushed != nil {
+		r.pushed()
+	}
+	return ErrQueueFull
+}
+
+func TestSerialQueue_PushRejected(t *testing.T) {
+	var (
+		q      = new(serialQueue)
+		got    []string
+		drains int
+	)
+	var executor = rejectExecutor{pushed: func() {
+		// the task pushed by others while Execute
+		ok, err := q.push(GoExecutor{}, func() {
+			got = append(got, "b")
+		}, nil)
+		if !ok || err != nil {
+			t.Errorf("push() = %v, %v, want true, nil", ok, err)
+		}
+	}}
+
+	ok, err := q.push(executor, func() {
+		got = append(got, "a")
+	}, func() {
+		drains++
+	})
+	if !ok || err != ErrQueueFull {
+		t.Fatalf("push() = %v, %v, want true, %v", ok, err, ErrQueueFull)
+	}
+	if len(got) != 1 || got[0] != "b" {
+		t.Fatalf("want the accepted task done, got %v", got)
+	}
+	if q.running || len(q.tasks) != 0 || drains != 1 {
+		t.Fatalf("want queue drained, running %v, tasks %d, drains %d", q.running, len(q.tasks), drains)
+	}
+}
@@ -0,0 +1,204 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrQuorumNotReached = errors.New("gather quorum not reached")
+)
+
+// GatherPolicy is the completion policy of Gather, it's the count of succeeded results to complete, zero is all.
+type GatherPolicy int
+
+const (
+	GatherAll   GatherPolicy = 0 // GatherAll completes when all callbacks done.
+	GatherFirst GatherPolicy = 1 // GatherFirst completes when the first callback succeeded.
+)
+
+// GatherQuorum completes when n callbacks succeeded, not positive n is GatherAll.
+func GatherQuorum(n int) GatherPolicy {
+	if n < 0 {
+		n = 0
+	}
+	return GatherPolicy(n)
+}
+
+// GatherResult is the result of a subscribed callback in Gather.
+type GatherResult struct {
+	Subscription uint64        // Subscription is the Subscription id of callback.
+	Event        string        // Event is the subscribed event name of callback, it can be wildcard.
+	Value        interface{}   // Value is the reply of responder, it's nil for the other subscribers.
+	Err          error         // Err is the callback error, it's context.Canceled when canceled after completed.
+	Duration     time.Duration // Duration of callback done, or until canceled.
+}
+
+// gathered result of the callback index.
+type gathered struct {
+	idx    int           // idx is the callback index.
+	result *GatherResult // result of callback.
+}
+
+// Gather publish event with args and publish option by context to all callbacks concurrently, returns the results in subscribed order.
+// the value of responder subscribed by Respond is collected, the other subscribers are done with nil value.
+// it completes by policy, the not done callbacks are canceled by context when completed and their result error is context.Canceled.
+// returns ErrNotExistEvent when no subscriber, ErrQuorumNotReached when policy can't be completed, the context error when ctx is done,
+// otherwise the Errors of failed callbacks in GatherAll policy.
+// the publish is intercepted by Event interceptors before dispatch.
+func (e *Event) Gather(ctx context.Context, name string, policy GatherPolicy, args ...interface{}) ([]*GatherResult, error) {
+	var results []*GatherResult
+	var err = e.intercept(func(ctx context.Context, name string, args ...interface{}) error {
+		var err error
+		results, err = e.scatter(ctx, name, policy, args...)
+		return err
+	})(ctx, name, args...)
+	return results, err
+}
+
+// scatter publish to all callbacks concurrently and gather the results by policy, it's the Gather PublishHandler.
+func (e *Event) scatter(ctx context.Context, name string, policy GatherPolicy, args ...interface{}) ([]*GatherResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var publishOptions = GetPublishOptionsFromContext(ctx)
+	var env, events, _ = e.prepare(name, publishOptions, args)
+	e.published(name)
+
+	ctx = context.WithValue(ctx, topicCtxKey{}, env.Name)
+	ctx = context.WithValue(ctx, envelopeCtxKey{}, env)
+
+	var list = e.gatherCallbacks(ctx, events, env)
+	if len(list) == 0 {
+		return nil, ErrNotExistEvent
+	}
+
+	// GatherAll is not completed by quorum
+	var quorum = int(policy)
+	if policy != GatherAll && len(list) < quorum {
+		return nil, fmt.Errorf("%w: %d callbacks for quorum %d", ErrQuorumNotReached, len(list), quorum)
+	}
+	for _, cb := range list {
+		if cb.subscribeOptions != nil && cb.subscribeOptions.Once {
+			e.remove(cb)
+		}
+	}
+
+	var metrics = e.getOptions().Metrics
+	metrics.Dispatch(env.Name, 1)
+	defer metrics.Dispatch(env.Name, -1)
+
+	gatherCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var start = e.now()
+	var done = make(chan gathered, len(list))
+	for idx, cb := range list {
+		go e.gather(gatherCtx, idx, cb, done, args...)
+	}
+
+	var results = make([]*GatherResult, len(list))
+	var succeeded, pending = 0, len(list)
+	// cancel the not done callbacks
+	var complete = func(err error) ([]*GatherResult, error) {
+		cancel()
+		for idx, cb := range list {
+			if results[idx] == nil {
+				results[idx] = &GatherResult{
+					Subscription: cb.id,
+					Event:        cb.name,
+					Err:          context.Canceled,
+					Duration:     e.now().Sub(start),
+				}
+			}
+		}
+		return results, err
+	}
+
+	for pending > 0 {
+		if policy != GatherAll && succeeded >= quorum {
+			return complete(nil)
+		}
+		if succeeded+pending < quorum {
+			return complete(fmt.Errorf("%w: %d of %d succeeded", ErrQuorumNotReached, succeeded, quorum))
+		}
+		select {
+		case item := <-done:
+			pending--
+			results[item.idx] = item.result
+			if item.result.Err == nil {
+				succeeded++
+			}
+		case <-ctx.Done():
+			return complete(ctx.Err())
+		}
+	}
+	if policy != GatherAll {
+		if succeeded < quorum {
+			return results, fmt.Errorf("%w: %d of %d succeeded", ErrQuorumNotReached, succeeded, quorum)
+		}
+		return results, nil
+	}
+
+	var errs Errors
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return results, errs.Nil()
+}
+
+// gatherCallbacks returns the callbacks which accept the publish.
+func (e *Event) gatherCallbacks(ctx context.Context, events []*event, env *Envelope) []*callback {
+	var result []*callback
+	var now = e.now()
+	for _, event := range events {
+		event.mu.Lock()
+		var list = event.callbacks
+		event.mu.Unlock()
+
+		for _, cb := range list {
+			if cb.f == nil || !cb.active() || cb.exhausted(now) {
+				continue
+			}
+			if !e.filter(ctx, cb, env.Payload...) {
+				continue
+			}
+			if cb.limiter != nil && !cb.limiter.offer(env) {
+				continue
+			}
+			result = append(result, cb)
+		}
+	}
+	return result
+}
+
+// gather done the callback and sends the result, the failed is put into the DeadLetterSink when not canceled.
+func (e *Event) gather(ctx context.Context, idx int, cb *callback, done chan<- gathered, args ...interface{}) {
+	// the reply is claimed, the publish in callback will not set it.
+	var r = &reply{
+		claimed: 1,
+		done:    make(chan struct{}),
+	}
+	var start = e.now()
+	var err = e.deliver(context.WithValue(ctx, replyCtxKey{}, r), cb, args...)
+	var result = &GatherResult{
+		Subscription: cb.id,
+		Event:        cb.name,
+		Err:          err,
+		Duration:     e.now().Sub(start),
+	}
+	if err == nil {
+		r.mu.Lock()
+		result.Value = r.value
+		r.mu.Unlock()
+	}
+	if ctx.Err() == nil {
+		e.deadLetter(ctx, cb, err, args...)
+	}
+	done <- gathered{idx: idx, result: result}
+}
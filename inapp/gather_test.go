@@ -0,0 +1,201 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvent_Gather(t *testing.T) {
+	var canceled = make(chan error, 4)
+
+	value := func(v interface{}) Responder {
+		return func(ctx context.Context, args ...interface{}) (interface{}, error) {
+			return v, nil
+		}
+	}
+	fail := func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		return nil, ErrTest
+	}
+	slow := func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return nil, ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		responders []Responder
+		observer   bool
+		policy     GatherPolicy
+		timeout    time.Duration
+		want       []interface{} // want values, error is the result error
+		wantErr    error
+		canceled   int // count of canceled stragglers
+	}{
+		{
+			name:       "all",
+			responders: []Responder{value(1), fail, value(3)},
+			observer:   true,
+			policy:     GatherAll,
+			want:       []interface{}{1, ErrTest, 3, nil},
+			wantErr:    Errors{ErrTest},
+		},
+		{
+			name:       "first",
+			responders: []Responder{slow, value(2), slow},
+			policy:     GatherFirst,
+			want:       []interface{}{context.Canceled, 2, context.Canceled},
+			canceled:   2,
+		},
+		{
+			name:       "quorum",
+			responders: []Responder{value(1), slow, value(3)},
+			policy:     GatherQuorum(2),
+			want:       []interface{}{1, context.Canceled, 3},
+			canceled:   1,
+		},
+		{
+			name:       "quorum not reached",
+			responders: []Responder{value(1), fail},
+			policy:     GatherQuorum(2),
+			wantErr:    ErrQuorumNotReached,
+		},
+		{
+			name:       "quorum more than callbacks",
+			responders: []Responder{value(1)},
+			policy:     GatherQuorum(2),
+			wantErr:    ErrQuorumNotReached,
+		},
+		{
+			name:       "timeout",
+			responders: []Responder{value(1), slow},
+			policy:     GatherAll,
+			timeout:    time.Millisecond * 20,
+			want:       []interface{}{1, context.Canceled},
+			wantErr:    context.DeadlineExceeded,
+			canceled:   1,
+		},
+		{
+			name:    "no subscriber",
+			policy:  GatherAll,
+			wantErr: ErrNotExistEvent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e = NewEvent()
+			for _, responder := range tt.responders {
+				e.Respond(context.TODO(), "scatter", responder)
+			}
+			if tt.observer {
+				e.Subscribe(context.TODO(), "scatter", func(ctx context.Context, args ...interface{}) error {
+					return nil
+				})
+			}
+
+			var ctx = context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			results, err := e.Gather(ctx, "scatter", tt.policy)
+			if !reflect.DeepEqual(err, tt.wantErr) && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+			if tt.want != nil {
+				var got []interface{}
+				for _, result := range results {
+					if result.Err != nil {
+						got = append(got, result.Err)
+					} else {
+						got = append(got, result.Value)
+					}
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("want %v, got %v", tt.want, got)
+				}
+			}
+			for i := 0; i < tt.canceled; i++ {
+				select {
+				case <-canceled:
+				case <-time.After(time.Second):
+					t.Fatalf("want %d stragglers canceled", tt.canceled)
+				}
+			}
+		})
+	}
+}
+
+func TestEvent_GatherResult(t *testing.T) {
+	var e = NewEvent()
+	s := e.Respond(context.TODO(), "scatter.*", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		time.Sleep(time.Millisecond * 10)
+		return args[0], nil
+	})
+
+	results, err := e.Gather(context.TODO(), "scatter.a", GatherAll, "v")
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("want 1 result, got %d", len(results))
+	}
+	var result = results[0]
+	if result.Subscription != s.ID() || result.Event != "scatter.*" || result.Value != "v" || result.Duration < time.Millisecond*10 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestEvent_GatherNotStarted(t *testing.T) {
+	var called int
+	var errDenied = errors.New("denied")
+
+	tests := []struct {
+		name        string
+		interceptor PublishInterceptor
+		policy      GatherPolicy
+		wantErr     error
+	}{
+		{
+			name: "intercepted",
+			interceptor: func(next PublishHandler) PublishHandler {
+				return func(ctx context.Context, name string, args ...interface{}) error {
+					return errDenied
+				}
+			},
+			policy:  GatherAll,
+			wantErr: errDenied,
+		},
+		{
+			name:    "quorum more than callbacks",
+			policy:  GatherQuorum(2),
+			wantErr: ErrQuorumNotReached,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = 0
+			var e = NewEvent()
+			if tt.interceptor != nil {
+				e.Intercept(tt.interceptor)
+			}
+			e.Respond(context.TODO(), "scatter", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+				called++
+				return nil, nil
+			})
+
+			results, err := e.Gather(context.TODO(), "scatter", tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+			if results != nil || called != 0 {
+				t.Fatalf("want callback not started, got %d calls and results %v", called, results)
+			}
+		})
+	}
+}
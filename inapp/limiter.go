@@ -0,0 +1,164 @@
+package inapp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limit mode of callback.
+type limitMode int
+
+const (
+	limitDebounce limitMode = iota + 1 // deliver the last publish after quiet interval.
+	limitThrottle                      // deliver at most one publish per interval.
+	limitSample                        // deliver the last publish every interval.
+)
+
+// limiter defers or drops the publish of callback by limit mode.
+type limiter struct {
+	mode     limitMode           // mode of limiter.
+	interval time.Duration       // interval of limit mode.
+	trailing bool                // trailing throttle deliver the last publish at the end of interval, otherwise the first.
+	clock    Clock               // clock of timer.
+	fire     func(env *Envelope) // fire delivers the deferred publish.
+	mu       sync.Mutex          // mu protects fields below.
+	timer    Timer               // timer of the pending interval.
+	last     *Envelope           // last is the deferred publish.
+	closed   bool                // closed is true when callback removed.
+}
+
+// newLimiter returns the limiter of subscribe options, returns nil when callback is not limited.
+// Debounce is preferred over Throttle and Throttle is preferred over Sample.
+func newLimiter(clock Clock, subscribeOptions *SubscribeOptions, fire func(env *Envelope)) *limiter {
+	if subscribeOptions == nil {
+		return nil
+	}
+	var l = &limiter{
+		clock: clock,
+		fire:  fire,
+	}
+	switch {
+	case subscribeOptions.Debounce > 0:
+		l.mode, l.interval = limitDebounce, subscribeOptions.Debounce
+	case subscribeOptions.Throttle > 0:
+		l.mode, l.interval, l.trailing = limitThrottle, subscribeOptions.Throttle, subscribeOptions.ThrottleTrailing
+	case subscribeOptions.Sample > 0:
+		l.mode, l.interval = limitSample, subscribeOptions.Sample
+	default:
+		return nil
+	}
+	return l
+}
+
+// start the sample ticking.
+func (l *limiter) start() {
+	if l.mode != limitSample {
+		return
+	}
+	l.mu.Lock()
+	if !l.closed && l.timer == nil {
+		l.timer = l.clock.AfterFunc(l.interval, l.tick)
+	}
+	l.mu.Unlock()
+}
+
+// offer the publish Envelope, returns true when it's delivered now, otherwise it's deferred or dropped.
+func (l *limiter) offer(env *Envelope) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+
+	switch l.mode {
+	case limitDebounce:
+		// restart the quiet interval
+		if l.timer != nil {
+			l.timer.Stop()
+		}
+		l.last = env
+		l.timer = l.clock.AfterFunc(l.interval, l.flush)
+		return false
+	case limitThrottle:
+		if l.trailing {
+			l.last = env
+			if l.timer == nil {
+				l.timer = l.clock.AfterFunc(l.interval, l.flush)
+			}
+			return false
+		}
+		if l.timer != nil { // dropped in interval
+			return false
+		}
+		l.timer = l.clock.AfterFunc(l.interval, l.flush)
+		return true
+	default:
+		l.last = env
+		return false
+	}
+}
+
+// flush the deferred publish at the end of interval.
+func (l *limiter) flush() {
+	l.mu.Lock()
+	var env = l.last
+	l.last, l.timer = nil, nil
+	var closed = l.closed
+	l.mu.Unlock()
+
+	if env != nil && !closed {
+		l.fire(env)
+	}
+}
+
+// tick delivers the last publish of sample interval and starts the next.
+func (l *limiter) tick() {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	var env = l.last
+	l.last = nil
+	l.timer = l.clock.AfterFunc(l.interval, l.tick)
+	l.mu.Unlock()
+
+	if env != nil {
+		l.fire(env)
+	}
+}
+
+// stop the pending timer, the deferred publish is dropped.
+func (l *limiter) stop() {
+	l.mu.Lock()
+	l.closed = true
+	l.last = nil
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.mu.Unlock()
+}
+
+// deliverDeferred done the deferred publish Envelope of the rate limited callback.
+// the failed delivery is put into the DeadLetterSink.
+func (e *Event) deliverDeferred(cb *callback, env *Envelope) {
+	actual, ok := e.list.Load(cb.name)
+	if !ok {
+		return
+	}
+	var event = actual.(*event)
+	doneLock, _ := e.lock(context.Background(), event)
+	if doneLock == nil { // event removed
+		return
+	}
+	defer e.unlock(event, doneLock)
+
+	if !cb.active() || cb.exhausted(e.now()) {
+		return
+	}
+	var ctx = context.WithValue(context.Background(), topicCtxKey{}, env.Name)
+	ctx = context.WithValue(ctx, envelopeCtxKey{}, env)
+	e.deliverEnvelope(ctx, event, cb, env)
+}
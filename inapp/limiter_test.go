@@ -0,0 +1,182 @@
+package inapp
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manual Clock, the timers are fired in Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	c    *fakeClock
+	at   time.Time
+	f    func()
+	done bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{c: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	if t.done {
+		return false
+	}
+	t.done = true
+	return true
+}
+
+// Advance the clock by d, fire the due timers in time order.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	var target = c.now.Add(d)
+	for {
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if !t.done && !t.at.After(target) && (next == nil || t.at.Before(next.at)) {
+				next = t
+			}
+		}
+		if next == nil {
+			break
+		}
+		next.done = true
+		c.now = next.at
+		c.mu.Unlock()
+		next.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+// Pending returns the count of not fired and not stopped timers.
+func (c *fakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for _, t := range c.timers {
+		if !t.done {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEvent_SubscribeLimit(t *testing.T) {
+	const interval = time.Millisecond * 100
+
+	// steps: publish value, or advance the clock when value is 0
+	type step struct {
+		value   int
+		advance time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		opts  []SubscribeOption
+		steps []step
+		want  []int
+	}{
+		{
+			name: "debounce",
+			opts: []SubscribeOption{WithDebounceOption(interval)},
+			steps: []step{
+				{value: 1}, {advance: interval / 2}, {value: 2}, {advance: interval / 2}, {value: 3},
+				{advance: interval},
+				{value: 4}, {advance: interval},
+			},
+			want: []int{3, 4},
+		},
+		{
+			name: "throttle leading",
+			opts: []SubscribeOption{WithThrottleOption(interval, false)},
+			steps: []step{
+				{value: 1}, {value: 2}, {advance: interval / 2}, {value: 3},
+				{advance: interval / 2},
+				{value: 4}, {value: 5}, {advance: interval},
+			},
+			want: []int{1, 4},
+		},
+		{
+			name: "throttle trailing",
+			opts: []SubscribeOption{WithThrottleOption(interval, true)},
+			steps: []step{
+				{value: 1}, {value: 2}, {advance: interval / 2}, {value: 3},
+				{advance: interval / 2},
+				{value: 4}, {advance: interval},
+			},
+			want: []int{3, 4},
+		},
+		{
+			name: "sample",
+			opts: []SubscribeOption{WithSampleOption(interval)},
+			steps: []step{
+				{advance: interval / 2}, {value: 1}, {value: 2},
+				{advance: interval / 2},
+				{advance: interval},
+				{value: 3}, {advance: interval},
+			},
+			want: []int{2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clock = newFakeClock()
+			var e = NewEvent(WithClock(clock))
+			var got []int
+			s := e.Subscribe(NewSubscribeOptionContext(context.TODO(), tt.opts...), "test", func(ctx context.Context, args ...interface{}) error {
+				got = append(got, args[0].(int))
+				return nil
+			})
+			for _, step := range tt.steps {
+				if step.value == 0 {
+					clock.Advance(step.advance)
+					continue
+				}
+				if err := e.PublishSync(context.TODO(), "test", step.value); err != nil {
+					t.Fatalf("PublishSync() error = %v", err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+
+			// timers are stopped by Unsubscribe, the deferred publish is dropped
+			e.PublishSync(context.TODO(), "test", -1)
+			var want = append([]int(nil), got...)
+			s.Unsubscribe()
+			if n := clock.Pending(); n != 0 {
+				t.Fatalf("want no pending timer, got %d", n)
+			}
+			clock.Advance(interval * 2)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("want %v after Unsubscribe, got %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package inapp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics collects the metrics of Event, the methods must be safe for concurrent use.
+// the subscriptions are identified by subscribed name.
+type Metrics interface {
+	// Publish is called when event published.
+	Publish(event string)
+	// Deliver is called when callback of subscribed name done with the latency include retries and the callback error.
+	Deliver(event string, subscribed string, latency time.Duration, err error)
+	// Panic is called when callback of subscribed name panicked.
+	Panic(event string, subscribed string)
+	// Dispatch is called with delta 1 when publish dispatch started and -1 when finished.
+	Dispatch(event string, delta int)
+	// QueueWait is called with the wait from async publish to dispatch started.
+	QueueWait(event string, wait time.Duration)
+}
+
+// nopMetrics is the Metrics does nothing.
+type nopMetrics struct{}
+
+func (nopMetrics) Publish(string)                               {}
+func (nopMetrics) Deliver(string, string, time.Duration, error) {}
+func (nopMetrics) Panic(string, string)                         {}
+func (nopMetrics) Dispatch(string, int)                         {}
+func (nopMetrics) QueueWait(string, time.Duration)              {}
+
+// DefaultBuckets are the default histogram upper bounds in seconds.
+var DefaultBuckets = []float64{.0005, .001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram is the distribution of durations in seconds.
+type Histogram struct {
+	Buckets []float64 // Buckets are the upper bounds in seconds.
+	Counts  []uint64  // Counts are the observations of bucket, the last is the observations greater than all bounds.
+	Count   uint64    // Count of observations.
+	Sum     float64   // Sum of observations in seconds.
+}
+
+// new Histogram with bucket upper bounds.
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		Buckets: buckets,
+		Counts:  make([]uint64, len(buckets)+1),
+	}
+}
+
+// observe the duration.
+func (h *Histogram) observe(d time.Duration) {
+	var v = d.Seconds()
+	h.Counts[sort.SearchFloat64s(h.Buckets, v)]++
+	h.Count++
+	h.Sum += v
+}
+
+// clone returns the copy of Histogram.
+func (h *Histogram) clone() Histogram {
+	var result = *h
+	result.Counts = append([]uint64(nil), h.Counts...)
+	return result
+}
+
+// EventMetrics is the metrics of published event name.
+type EventMetrics struct {
+	Published uint64    // Published is the count of publish.
+	Delivered uint64    // Delivered is the count of callback done.
+	Errors    uint64    // Errors is the count of callback failed.
+	Panics    uint64    // Panics is the count of callback panicked.
+	InFlight  int64     // InFlight is the count of publish in dispatch.
+	QueueWait Histogram // QueueWait is the wait from async publish to dispatch started.
+}
+
+// SubscriberMetrics is the metrics of subscribed event name for published event name.
+// the subscriptions of the same subscribed name are collected together, so the series are not grown by subscribe churn.
+type SubscriberMetrics struct {
+	Event      string    // Event is the published event name.
+	Subscribed string    // Subscribed is the subscribed event name, it can be wildcard.
+	Latency    Histogram // Latency of callback done include retries.
+}
+
+// subscriberKey is the key of subscriber metrics.
+type subscriberKey struct {
+	event      string
+	subscribed string
+}
+
+// MemoryMetrics is the in memory Metrics, it exposes the metrics in Prometheus text format by ServeHTTP.
+type MemoryMetrics struct {
+	buckets     []float64                            // buckets of histograms.
+	mu          sync.Mutex                           // mu protects fields below.
+	events      map[string]*EventMetrics             // events metrics by published name.
+	subscribers map[subscriberKey]*SubscriberMetrics // subscribers metrics by published name and subscribed name.
+}
+
+// New MemoryMetrics with histogram bucket upper bounds in seconds, DefaultBuckets is used when buckets is empty.
+func NewMemoryMetrics(buckets ...float64) *MemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &MemoryMetrics{
+		buckets:     buckets,
+		events:      make(map[string]*EventMetrics),
+		subscribers: make(map[subscriberKey]*SubscriberMetrics),
+	}
+}
+
+// event returns the metrics of event name, it must be called with mu held.
+func (m *MemoryMetrics) event(name string) *EventMetrics {
+	var metrics = m.events[name]
+	if metrics == nil {
+		metrics = &EventMetrics{QueueWait: *newHistogram(m.buckets)}
+		m.events[name] = metrics
+	}
+	return metrics
+}
+
+// Publish count the publish of event.
+func (m *MemoryMetrics) Publish(event string) {
+	m.mu.Lock()
+	m.event(event).Published++
+	m.mu.Unlock()
+}
+
+// Deliver count the delivery of event and observe the subscriber latency.
+func (m *MemoryMetrics) Deliver(event string, subscribed string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var metrics = m.event(event)
+	metrics.Delivered++
+	if err != nil {
+		metrics.Errors++
+	}
+
+	var key = subscriberKey{event: event, subscribed: subscribed}
+	var subscriber = m.subscribers[key]
+	if subscriber == nil {
+		subscriber = &SubscriberMetrics{
+			Event:      event,
+			Subscribed: subscribed,
+			Latency:    *newHistogram(m.buckets),
+		}
+		m.subscribers[key] = subscriber
+	}
+	subscriber.Latency.observe(latency)
+}
+
+// Panic count the callback panic of event.
+func (m *MemoryMetrics) Panic(event string, subscribed string) {
+	m.mu.Lock()
+	m.event(event).Panics++
+	m.mu.Unlock()
+}
+
+// Dispatch add delta to the in flight publish of event.
+func (m *MemoryMetrics) Dispatch(event string, delta int) {
+	m.mu.Lock()
+	m.event(event).InFlight += int64(delta)
+	m.mu.Unlock()
+}
+
+// QueueWait observe the queue wait of event.
+func (m *MemoryMetrics) QueueWait(event string, wait time.Duration) {
+	m.mu.Lock()
+	m.event(event).QueueWait.observe(wait)
+	m.mu.Unlock()
+}
+
+// Events returns the snapshot of events metrics by published name.
+func (m *MemoryMetrics) Events() map[string]EventMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result = make(map[string]EventMetrics, len(m.events))
+	for name, metrics := range m.events {
+		var item = *metrics
+		item.QueueWait = metrics.QueueWait.clone()
+		result[name] = item
+	}
+	return result
+}
+
+// Subscribers returns the snapshot of subscribers metrics ordered by published name and subscribed name.
+func (m *MemoryMetrics) Subscribers() []SubscriberMetrics {
+	m.mu.Lock()
+	var result = make([]SubscriberMetrics, 0, len(m.subscribers))
+	for _, metrics := range m.subscribers {
+		var item = *metrics
+		item.Latency = metrics.Latency.clone()
+		result = append(result, item)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Event != result[j].Event {
+			return result[i].Event < result[j].Event
+		}
+		return result[i].Subscribed < result[j].Subscribed
+	})
+	return result
+}
+
+// ServeHTTP writes the metrics in Prometheus text format.
+func (m *MemoryMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in Prometheus text format to w.
+func (m *MemoryMetrics) WriteTo(w io.Writer) (int64, error) {
+	var events = m.Events()
+	var names = make([]string, 0, len(events))
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pw = &promWriter{w: bufio.NewWriter(w)}
+	var counters = []struct {
+		name, help string
+		value      func(EventMetrics) string
+	}{
+		{"inapp_published_total", "The count of publish.", func(v EventMetrics) string { return strconv.FormatUint(v.Published, 10) }},
+		{"inapp_delivered_total", "The count of callback done.", func(v EventMetrics) string { return strconv.FormatUint(v.Delivered, 10) }},
+		{"inapp_errors_total", "The count of callback failed.", func(v EventMetrics) string { return strconv.FormatUint(v.Errors, 10) }},
+		{"inapp_panics_total", "The count of callback panicked.", func(v EventMetrics) string { return strconv.FormatUint(v.Panics, 10) }},
+	}
+	for _, counter := range counters {
+		pw.header(counter.name, counter.help, "counter")
+		for _, name := range names {
+			pw.sample(counter.name, labels{{"event", name}}, counter.value(events[name]))
+		}
+	}
+
+	pw.header("inapp_inflight_dispatches", "The count of publish in dispatch.", "gauge")
+	for _, name := range names {
+		pw.sample("inapp_inflight_dispatches", labels{{"event", name}}, strconv.FormatInt(events[name].InFlight, 10))
+	}
+
+	pw.header("inapp_queue_wait_seconds", "The wait from async publish to dispatch started.", "histogram")
+	for _, name := range names {
+		var h = events[name].QueueWait
+		pw.histogram("inapp_queue_wait_seconds", labels{{"event", name}}, &h)
+	}
+
+	pw.header("inapp_delivery_latency_seconds", "The latency of callback done include retries.", "histogram")
+	for _, subscriber := range m.Subscribers() {
+		var ls = labels{
+			{"event", subscriber.Event},
+			{"subscribed", subscriber.Subscribed},
+		}
+		pw.histogram("inapp_delivery_latency_seconds", ls, &subscriber.Latency)
+	}
+
+	if pw.err == nil {
+		pw.err = pw.w.Flush()
+	}
+	return pw.n, pw.err
+}
+
+// labels of Prometheus sample in order.
+type labels [][2]string
+
+// promWriter writes Prometheus text format, the first error is kept.
+type promWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (pw *promWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+// header writes the HELP and TYPE lines of metric.
+func (pw *promWriter) header(name, help, typ string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes a sample line of metric.
+func (pw *promWriter) sample(name string, ls labels, value string) {
+	var buf strings.Builder
+	for idx, label := range ls {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(label[0])
+		buf.WriteString(`="`)
+		buf.WriteString(escapeLabel(label[1]))
+		buf.WriteByte('"')
+	}
+	pw.printf("%s{%s} %s\n", name, buf.String(), value)
+}
+
+// histogram writes the cumulative buckets, sum and count samples of histogram.
+func (pw *promWriter) histogram(name string, ls labels, h *Histogram) {
+	var cumulative uint64
+	for idx, bound := range h.Buckets {
+		cumulative += h.Counts[idx]
+		pw.sample(name+"_bucket", append(ls[:len(ls):len(ls)], [2]string{"le", strconv.FormatFloat(bound, 'g', -1, 64)}), strconv.FormatUint(cumulative, 10))
+	}
+	pw.sample(name+"_bucket", append(ls[:len(ls):len(ls)], [2]string{"le", "+Inf"}), strconv.FormatUint(h.Count, 10))
+	pw.sample(name+"_sum", ls, strconv.FormatFloat(h.Sum, 'g', -1, 64))
+	pw.sample(name+"_count", ls, strconv.FormatUint(h.Count, 10))
+}
+
+// escapeLabel escapes the label value of Prometheus text format.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// topic returns the published event name of callback, it's the subscribed name out of publish.
+func topic(ctx context.Context, cb *callback) string {
+	if name, ok := GetTopicFromContext(ctx); ok {
+		return name
+	}
+	return cb.name
+}
@@ -0,0 +1,130 @@
+package inapp
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent_Metrics(t *testing.T) {
+	var metrics = NewMemoryMetrics()
+	var e = NewEvent(WithMetrics(metrics))
+	e.Subscribe(context.TODO(), "order.*", func(ctx context.Context, args ...interface{}) error {
+		switch args[0].(int) {
+		case 2:
+			return ErrTest
+		case 3:
+			panic("boom")
+		}
+		return nil
+	})
+	e.Subscribe(context.TODO(), "order.created", func(ctx context.Context, args ...interface{}) error {
+		return nil
+	})
+
+	for i := 1; i <= 3; i++ {
+		e.PublishSync(context.TODO(), "order.created", i)
+	}
+	e.PublishSync(context.TODO(), "order.paid", 1)
+	var result = make(chan error, 1)
+	e.Publish(NewPublishOptionContext(context.TODO(), WithErrorOption(result)), "order.paid", 1)
+	<-result
+
+	var events = metrics.Events()
+	var tests = []struct {
+		event string
+		want  EventMetrics
+	}{
+		{"order.created", EventMetrics{Published: 3, Delivered: 6, Errors: 2, Panics: 1}},
+		{"order.paid", EventMetrics{Published: 2, Delivered: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			var got = events[tt.event]
+			if got.Published != tt.want.Published || got.Delivered != tt.want.Delivered ||
+				got.Errors != tt.want.Errors || got.Panics != tt.want.Panics || got.InFlight != 0 {
+				t.Fatalf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+	if got := events["order.paid"].QueueWait.Count; got != 1 {
+		t.Fatalf("want 1 queue wait, got %d", got)
+	}
+
+	var subscribers = metrics.Subscribers()
+	if len(subscribers) != 3 {
+		t.Fatalf("want 3 subscribers, got %d", len(subscribers))
+	}
+	if got := subscribers[0]; got.Event != "order.created" || got.Subscribed != "order.*" || got.Latency.Count != 3 {
+		t.Fatalf("unexpected subscriber %+v", got)
+	}
+
+	// the subscribe churn of the same name is collected together
+	for i := 0; i < 100; i++ {
+		e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithOnceOption(true)), "order.paid", func(ctx context.Context, args ...interface{}) error {
+			return nil
+		})
+		e.PublishSync(context.TODO(), "order.paid", 1)
+	}
+	if got := len(metrics.Subscribers()); got != 4 {
+		t.Fatalf("want 4 subscribers, got %d", got)
+	}
+}
+
+func TestMemoryMetrics_Histogram(t *testing.T) {
+	var metrics = NewMemoryMetrics(1, 0.1)
+	for _, d := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+		metrics.QueueWait("test", d)
+	}
+
+	var got = metrics.Events()["test"].QueueWait
+	var want = []uint64{2, 1, 1}
+	for idx := range want {
+		if got.Counts[idx] != want[idx] {
+			t.Fatalf("want counts %v, got %v", want, got.Counts)
+		}
+	}
+	if got.Count != 4 || got.Sum < 2.649 || got.Sum > 2.651 {
+		t.Fatalf("unexpected count %d sum %v", got.Count, got.Sum)
+	}
+}
+
+func TestMemoryMetrics_ServeHTTP(t *testing.T) {
+	var metrics = NewMemoryMetrics(0.1)
+	metrics.Publish(`a"b`)
+	metrics.Dispatch(`a"b`, 1)
+	metrics.Deliver(`a"b`, "a.*", 50*time.Millisecond, ErrTest)
+	metrics.Panic(`a"b`, "a.*")
+
+	var recorder = httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	body, _ := io.ReadAll(recorder.Body)
+
+	var tests = []string{
+		"# TYPE inapp_published_total counter",
+		`inapp_published_total{event="a\"b"} 1`,
+		`inapp_delivered_total{event="a\"b"} 1`,
+		`inapp_errors_total{event="a\"b"} 1`,
+		`inapp_panics_total{event="a\"b"} 1`,
+		"# TYPE inapp_inflight_dispatches gauge",
+		`inapp_inflight_dispatches{event="a\"b"} 1`,
+		"# TYPE inapp_queue_wait_seconds histogram",
+		`inapp_queue_wait_seconds_count{event="a\"b"} 0`,
+		"# TYPE inapp_delivery_latency_seconds histogram",
+		`inapp_delivery_latency_seconds_bucket{event="a\"b",subscribed="a.*",le="0.1"} 1`,
+		`inapp_delivery_latency_seconds_bucket{event="a\"b",subscribed="a.*",le="+Inf"} 1`,
+		`inapp_delivery_latency_seconds_sum{event="a\"b",subscribed="a.*"} 0.05`,
+		`inapp_delivery_latency_seconds_count{event="a\"b",subscribed="a.*"} 1`,
+	}
+	for _, want := range tests {
+		if !strings.Contains(string(body), want+"\n") {
+			t.Fatalf("want line %q in\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package inapp
+
+import (
+	"context"
+)
+
+// Handler is the subscribed callback func.
+type Handler func(ctx context.Context, args ...interface{}) error
+
+// Filter reports whether the callback accepts the publish args.
+type Filter func(ctx context.Context, args ...interface{}) bool
+
+// Middleware wraps the next Handler, it's done around the callback.
+type Middleware func(next Handler) Handler
+
+// PublishHandler publishes event with args.
+type PublishHandler func(ctx context.Context, name string, args ...interface{}) error
+
+// PublishInterceptor wraps the next PublishHandler, it can inspect, mutate or reject a publish by returns error without calling next.
+type PublishInterceptor func(next PublishHandler) PublishHandler
+
+// Use subscriber middleware for all callbacks, the first is the outermost.
+// it's done outside of the subscription middleware set by WithMiddlewareOption.
+func (e *Event) Use(middleware ...Middleware) {
+	e.mu.Lock()
+	e.middleware = append(e.middleware[:len(e.middleware):len(e.middleware)], middleware...)
+	e.mu.Unlock()
+}
+
+// Intercept Publish, PublishSync, Request and Gather by interceptors before dispatch, the first is the outermost.
+func (e *Event) Intercept(interceptors ...PublishInterceptor) {
+	e.mu.Lock()
+	e.interceptors = append(e.interceptors[:len(e.interceptors):len(e.interceptors)], interceptors...)
+	e.mu.Unlock()
+}
+
+// handler returns the callback Handler wrapped by subscription and Event middleware.
+func (e *Event) handler(cb *callback) Handler {
+	var h = Handler(cb.f)
+	if cb.subscribeOptions != nil {
+		h = chainMiddleware(h, cb.subscribeOptions.Middleware)
+	}
+
+	e.mu.RLock()
+	var middleware = e.middleware
+	e.mu.RUnlock()
+
+	return chainMiddleware(h, middleware)
+}
+
+// intercept returns the PublishHandler wrapped by Event interceptors.
+func (e *Event) intercept(h PublishHandler) PublishHandler {
+	e.mu.RLock()
+	var interceptors = e.interceptors
+	e.mu.RUnlock()
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		h = interceptors[i](h)
+	}
+	return h
+}
+
+// chainMiddleware wraps h by middleware, the first is the outermost.
+func chainMiddleware(h Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,88 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEvent_Use(t *testing.T) {
+	var order []string
+
+	newMiddleware := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, args ...interface{}) error {
+				order = append(order, name+" before")
+				err := next(ctx, args...)
+				order = append(order, name+" after")
+				return err
+			}
+		}
+	}
+
+	e := NewEvent()
+	e.Use(newMiddleware("event1"), newMiddleware("event2"))
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithMiddlewareOption(newMiddleware("sub"))), "test", func(ctx context.Context, args ...interface{}) error {
+		order = append(order, "callback")
+		return nil
+	})
+
+	if err := e.PublishSync(context.TODO(), "test"); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	want := []string{"event1 before", "event2 before", "sub before", "callback", "sub after", "event2 after", "event1 after"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("want order %v, got %v", want, order)
+	}
+
+	// middleware panic is recovered.
+	e.Use(func(next Handler) Handler {
+		return func(ctx context.Context, args ...interface{}) error {
+			panic(ErrPanic)
+		}
+	})
+	if err := e.PublishSync(context.TODO(), "test"); !errors.Is(err, ErrPanic) || !errors.Is(err, ErrCallbackPanic) {
+		t.Fatalf("want error %v, got %v", ErrPanic, err)
+	}
+}
+
+func TestEvent_Intercept(t *testing.T) {
+	var got []interface{}
+
+	e := NewEvent()
+	e.Subscribe(context.TODO(), "test", func(ctx context.Context, args ...interface{}) error {
+		got = append(got, args...)
+		return nil
+	})
+	e.Intercept(
+		// reject
+		func(next PublishHandler) PublishHandler {
+			return func(ctx context.Context, name string, args ...interface{}) error {
+				if len(args) == 0 {
+					return ErrTest
+				}
+				return next(ctx, name, args...)
+			}
+		},
+		// mutate
+		func(next PublishHandler) PublishHandler {
+			return func(ctx context.Context, name string, args ...interface{}) error {
+				return next(ctx, name, append(args, "intercepted")...)
+			}
+		},
+	)
+
+	if err := e.PublishSync(context.TODO(), "test"); err != ErrTest {
+		t.Fatalf("want error %v, got %v", ErrTest, err)
+	}
+	if err := e.Publish(context.TODO(), "test"); err != ErrTest {
+		t.Fatalf("want error %v, got %v", ErrTest, err)
+	}
+	if err := e.PublishSync(context.TODO(), "test", "arg"); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if want := []interface{}{"arg", "intercepted"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want args %v, got %v", want, got)
+	}
+}
@@ -1,11 +1,127 @@
 package inapp
 
+import (
+	"time"
+)
+
+// Event option func.
+type EventOption func(options *EventOptions)
+
+// Event options.
+type EventOptions struct {
+	Executor    Executor       // Executor executes the async publish, default is GoExecutor.
+	Ordered     bool           // Ordered mode, async publish of the same event name are done in publish order.
+	DeadLetter  DeadLetterSink // DeadLetter receives the terminally failed deliveries, nil is dropped.
+	Timeout     time.Duration  // Timeout of every callback done, zero is no timeout.
+	ReplaySize  int            // ReplaySize is the max count of recent publish kept for replay of every event name, zero is unlimited when ReplayAge set.
+	ReplayAge   time.Duration  // ReplayAge is the max age of recent publish kept for replay, zero is unlimited when ReplaySize set.
+	Clock       Clock          // Clock is the time source of timers and publish time, default is SystemClock.
+	PanicPolicy PanicPolicy    // PanicPolicy is the handling of callback panic, default is PanicRecover.
+	OnPanic     PanicHook      // OnPanic is called with every callback panic before PanicPolicy handling.
+	Metrics     Metrics        // Metrics collects the publish and delivery metrics, default collects nothing.
+}
+
+// Get default EventOptions value.
+func GetDefaultEventOptions() *EventOptions {
+	opts := &EventOptions{
+		Executor: GoExecutor{},
+		Clock:    SystemClock{},
+		Metrics:  nopMetrics{},
+	}
+	return opts
+}
+
+// WithExecutor set the Executor of async publish, ignored when executor is nil.
+func WithExecutor(executor Executor) EventOption {
+	return func(options *EventOptions) {
+		if executor != nil {
+			options.Executor = executor
+		}
+	}
+}
+
+// WithOrdered will done async publish of the same event name in publish order, different names are still done concurrently.
+func WithOrdered(ordered bool) EventOption {
+	return func(options *EventOptions) {
+		options.Ordered = ordered
+	}
+}
+
+// WithDeadLetter set the DeadLetterSink of terminally failed deliveries.
+func WithDeadLetter(sink DeadLetterSink) EventOption {
+	return func(options *EventOptions) {
+		options.DeadLetter = sink
+	}
+}
+
+// WithDefaultTimeout set the default timeout of every callback done, it's overridden by WithTimeoutOption.
+func WithDefaultTimeout(timeout time.Duration) EventOption {
+	return func(options *EventOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// WithReplayBuffer keep the recent publish of every event name for replay, it's bounded by count size and age.
+// the replay is disabled when both size and age are zero, see WithReplayOption.
+func WithReplayBuffer(size int, age time.Duration) EventOption {
+	return func(options *EventOptions) {
+		options.ReplaySize = size
+		options.ReplayAge = age
+	}
+}
+
+// WithClock set the time source of Event, ignored when clock is nil.
+func WithClock(clock Clock) EventOption {
+	return func(options *EventOptions) {
+		if clock != nil {
+			options.Clock = clock
+		}
+	}
+}
+
+// WithPanicPolicy set the handling of callback panic.
+func WithPanicPolicy(policy PanicPolicy) EventOption {
+	return func(options *EventOptions) {
+		options.PanicPolicy = policy
+	}
+}
+
+// WithOnPanic set the hook of callback panic, it's called before PanicPolicy handling.
+func WithOnPanic(hook PanicHook) EventOption {
+	return func(options *EventOptions) {
+		options.OnPanic = hook
+	}
+}
+
+// WithMetrics set the Metrics of Event, ignored when metrics is nil.
+func WithMetrics(metrics Metrics) EventOption {
+	return func(options *EventOptions) {
+		if metrics != nil {
+			options.Metrics = metrics
+		}
+	}
+}
+
 // Subscribe option func.
 type SubscribeOption func(options *SubscribeOptions)
 
 // Subscribe options.
 type SubscribeOptions struct {
-	Once bool // Listen for a Event, but only once. The listener will be removed once it triggers for the first time.
+	Once             bool          // Listen for a Event, but only once. The listener will be removed once it triggers for the first time.
+	Partitions       int           // Partitions count, publish with the same partition key are done in order, different keys are done concurrently.
+	Priority         int           // Priority of callback, the higher is done first, the same priority are done in subscribed order.
+	Middleware       []Middleware  // Middleware of callback, it's done inside the Event middleware.
+	Retry            *RetryPolicy  // Retry policy of the failed callback, nil is no retry.
+	Timeout          time.Duration // Timeout of callback done, it's every attempt timeout when retry, zero is the Event default timeout.
+	Replay           int           // Replay count of the recent publish before live publish, it requires the Event replay buffer.
+	ReplaySince      time.Time     // ReplaySince replay the recent publish since the time before live publish, it requires the Event replay buffer.
+	Filter           Filter        // Filter of publish args, the callback is skipped when it returns false.
+	MaxDeliveries    int           // MaxDeliveries is the max count of successful deliveries, the callback is removed when reached, zero is unlimited.
+	Expiry           time.Time     // Expiry is the deadline of subscription, the callback is removed when expired, zero is never.
+	Debounce         time.Duration // Debounce delivers the last publish after the quiet interval.
+	Throttle         time.Duration // Throttle delivers at most one publish per interval.
+	ThrottleTrailing bool          // ThrottleTrailing delivers the last publish at the end of throttle interval, otherwise the first publish is delivered at once.
+	Sample           time.Duration // Sample delivers the last publish every interval.
 }
 
 // Get default SubscribeOptions value.
@@ -21,13 +137,112 @@ func WithOnceOption(once bool) SubscribeOption {
 	}
 }
 
+// WithPartitionsOption set the partitions count of callback, publish with partition key are hashed onto partitions.
+func WithPartitionsOption(partitions int) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Partitions = partitions
+	}
+}
+
+// WithPriorityOption set the callback priority, the higher is done first, default is 0.
+func WithPriorityOption(priority int) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Priority = priority
+	}
+}
+
+// WithMiddlewareOption append the callback middleware, the first is the outermost.
+func WithMiddlewareOption(middleware ...Middleware) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Middleware = append(options.Middleware, middleware...)
+	}
+}
+
+// WithRetryOption set the retry policy of the failed callback.
+func WithRetryOption(policy RetryPolicy) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Retry = &policy
+	}
+}
+
+// WithTimeoutOption set the timeout of callback done, the callback context is canceled and the next callback is done when timeout exceeded.
+func WithTimeoutOption(timeout time.Duration) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// WithReplayOption replay the last n recent publish in publish order before live publish, see WithReplayBuffer.
+func WithReplayOption(n int) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Replay = n
+	}
+}
+
+// WithReplaySinceOption replay the recent publish since t in publish order before live publish, see WithReplayBuffer.
+func WithReplaySinceOption(t time.Time) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.ReplaySince = t
+	}
+}
+
+// WithFilterOption set the filter of publish args, the callback is skipped without delivery when filter returns false.
+// the publish Envelope can be got from ctx by GetEnvelopeFromContext.
+func WithFilterOption(filter Filter) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Filter = filter
+	}
+}
+
+// WithMaxDeliveriesOption remove the callback after n successful deliveries, the failed deliveries are not counted.
+// the in progress deliveries of partitions may exceed n.
+func WithMaxDeliveriesOption(n int) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.MaxDeliveries = n
+	}
+}
+
+// WithExpiryOption remove the callback when deadline reached, the callback is not done after deadline.
+func WithExpiryOption(deadline time.Time) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Expiry = deadline
+	}
+}
+
+// WithDebounceOption delivers only the last publish after no publish in interval.
+// the deferred publish is done out of publish, the failed is put into the DeadLetterSink.
+func WithDebounceOption(interval time.Duration) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Debounce = interval
+	}
+}
+
+// WithThrottleOption delivers at most one publish per interval, the others are dropped.
+// the first publish of interval is delivered at once, or the last publish is delivered at the end of interval when trailing.
+func WithThrottleOption(interval time.Duration, trailing bool) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Throttle = interval
+		options.ThrottleTrailing = trailing
+	}
+}
+
+// WithSampleOption delivers the last publish every interval from Subscribe, nothing is delivered when no publish in interval.
+func WithSampleOption(interval time.Duration) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.Sample = interval
+	}
+}
+
 // Publish option func.
 type PublishOption func(options *PublishOptions)
 
 // Publish options.
 type PublishOptions struct {
-	Strict bool       // Strict mode, when done callback error strict is true will be stop and return.
-	Err    chan error // Err is finished signal, value is publish callback return.
+	Strict       bool              // Strict mode, when done callback error strict is true will be stop and return.
+	Err          chan error        // Err is finished signal, value is publish callback return.
+	PartitionKey string            // PartitionKey of partitioned callbacks, publish with the same key are done in order.
+	Headers      map[string]string // Headers of publish Envelope.
+	Retain       bool              // Retain the publish Envelope for the later subscribers of event name.
 }
 
 // Get default PublishOptions value.
@@ -49,3 +264,40 @@ func WithErrorOption(ch chan error) PublishOption {
 		options.Err = ch
 	}
 }
+
+// WithPartitionKeyOption set the partition key, it's hashed onto the partitions of partitioned callbacks.
+// async publish with partition key are dispatched in publish order of the event name.
+func WithPartitionKeyOption(key string) PublishOption {
+	return func(options *PublishOptions) {
+		options.PartitionKey = key
+	}
+}
+
+// WithHeaderOption set a header of publish Envelope.
+func WithHeaderOption(key, value string) PublishOption {
+	return func(options *PublishOptions) {
+		if options.Headers == nil {
+			options.Headers = make(map[string]string)
+		}
+		options.Headers[key] = value
+	}
+}
+
+// WithHeadersOption set headers of publish Envelope.
+func WithHeadersOption(headers map[string]string) PublishOption {
+	return func(options *PublishOptions) {
+		if options.Headers == nil {
+			options.Headers = make(map[string]string, len(headers))
+		}
+		for key, value := range headers {
+			options.Headers[key] = value
+		}
+	}
+}
+
+// WithRetainOption retain the publish Envelope as the last event of name, it's delivered to the later subscribers on Subscribe.
+func WithRetainOption(retain bool) PublishOption {
+	return func(options *PublishOptions) {
+		options.Retain = retain
+	}
+}
@@ -0,0 +1,70 @@
+package inapp
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the recovered panic of callback, it's matched by errors.Is with ErrCallbackPanic.
+type PanicError struct {
+	Value interface{} // Value is the recovered panic value.
+	Stack []byte      // Stack is the goroutine stack trace when panic.
+}
+
+// new PanicError of recovered value with the current stack trace.
+func newPanicError(v interface{}) *PanicError {
+	return &PanicError{
+		Value: v,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrCallbackPanic, e.Value)
+}
+
+// Is reports whether target is ErrCallbackPanic.
+func (e *PanicError) Is(target error) bool {
+	return target == ErrCallbackPanic
+}
+
+// Unwrap returns the panic value when it's error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// PanicPolicy is the handling of callback panic.
+type PanicPolicy int
+
+const (
+	PanicRecover     PanicPolicy = iota // PanicRecover recovers and reports the PanicError as callback error.
+	PanicUnsubscribe                    // PanicUnsubscribe recovers, reports and unsubscribes the panicked callback.
+	PanicRepanic                        // PanicRepanic panics again with the PanicError in the publish goroutine.
+)
+
+// PanicHook is called with the PanicError of callback, subscription is the panicked callback handle.
+type PanicHook func(ctx context.Context, subscription *Subscription, err *PanicError)
+
+// recovered handles the recovered panic value of callback by Event panic policy, returns the PanicError.
+func (e *Event) recovered(ctx context.Context, cb *callback, v interface{}) error {
+	// the re-panicked PanicError of nested publish
+	err, ok := v.(*PanicError)
+	var options = e.getOptions()
+	if !ok {
+		err = newPanicError(v)
+		options.Metrics.Panic(topic(ctx, cb), cb.name)
+	}
+
+	if options.OnPanic != nil {
+		options.OnPanic(ctx, newSubscription(e, cb.name, cb), err)
+	}
+	switch options.PanicPolicy {
+	case PanicUnsubscribe:
+		e.remove(cb)
+	case PanicRepanic:
+		panic(err)
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package inapp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvent_PanicPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    []EventOption
+		opts       []SubscribeOption
+		wantPanic  bool
+		wantActive bool
+	}{
+		{
+			name:       "recover",
+			wantActive: true,
+		},
+		{
+			name:       "unsubscribe",
+			options:    []EventOption{WithPanicPolicy(PanicUnsubscribe)},
+			wantActive: false,
+		},
+		{
+			name:       "repanic",
+			options:    []EventOption{WithPanicPolicy(PanicRepanic)},
+			wantPanic:  true,
+			wantActive: true,
+		},
+		{
+			name:       "repanic with timeout",
+			options:    []EventOption{WithPanicPolicy(PanicRepanic)},
+			opts:       []SubscribeOption{WithTimeoutOption(time.Second)},
+			wantPanic:  true,
+			wantActive: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hooked *PanicError
+			var hookedID uint64
+			var options = append([]EventOption{WithOnPanic(func(ctx context.Context, subscription *Subscription, err *PanicError) {
+				hooked, hookedID = err, subscription.ID()
+			})}, tt.options...)
+
+			var e = NewEvent(options...)
+			var count int
+			s := e.Subscribe(NewSubscribeOptionContext(context.TODO(), tt.opts...), "test", func(ctx context.Context, args ...interface{}) error {
+				if count++; count == 1 {
+					panic("boom")
+				}
+				return nil
+			})
+
+			var err error
+			var recovered interface{}
+			func() {
+				defer func() {
+					recovered = recover()
+				}()
+				err = e.PublishSync(context.TODO(), "test")
+			}()
+
+			var panicErr *PanicError
+			if tt.wantPanic {
+				var ok bool
+				if panicErr, ok = recovered.(*PanicError); !ok {
+					t.Fatalf("want panic PanicError, got %v", recovered)
+				}
+			} else {
+				if recovered != nil {
+					t.Fatalf("want no panic, got %v", recovered)
+				}
+				if !errors.As(err, &panicErr) || !errors.Is(err, ErrCallbackPanic) {
+					t.Fatalf("want PanicError, got %v", err)
+				}
+			}
+			if panicErr.Value != "boom" || !bytes.Contains(panicErr.Stack, []byte("panic_test.go")) {
+				t.Fatalf("unexpected PanicError %v\n%s", panicErr.Value, panicErr.Stack)
+			}
+			if hooked != panicErr || hookedID != s.ID() {
+				t.Fatalf("want hooked %v of subscription %d, got %v of %d", panicErr, s.ID(), hooked, hookedID)
+			}
+			if s.Active() != tt.wantActive {
+				t.Fatalf("want active %v", tt.wantActive)
+			}
+
+			// the event is still available after panic
+			err = e.PublishSync(context.TODO(), "test")
+			if tt.wantActive && err != nil {
+				t.Fatalf("PublishSync() error = %v", err)
+			}
+			if !tt.wantActive && err != ErrNotExistEvent {
+				t.Fatalf("want error %v, got %v", ErrNotExistEvent, err)
+			}
+		})
+	}
+}
+
+func TestEvent_PanicRepanicPool(t *testing.T) {
+	var pool = NewPoolExecutor(2, 10)
+	defer pool.Close()
+	var metrics = NewMemoryMetrics()
+	var e = NewEvent(WithExecutor(pool), WithOrdered(true), WithPanicPolicy(PanicRepanic), WithMetrics(metrics))
+	var count int
+	e.Subscribe(context.TODO(), "test", func(ctx context.Context, args ...interface{}) error {
+		if count++; count == 1 {
+			panic("boom")
+		}
+		return nil
+	})
+
+	var result = make(chan error, 2)
+	var ctx = NewPublishOptionContext(context.TODO(), WithErrorOption(result))
+	for i := 0; i < 2; i++ {
+		if err := e.Publish(ctx, "test", i); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	for i, want := range []error{ErrCallbackPanic, nil} {
+		select {
+		case err := <-result:
+			if !errors.Is(err, want) {
+				t.Fatalf("publish %d want error %v, got %v", i, want, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("publish %d is not reported", i)
+		}
+	}
+	if got := metrics.Events()["test"].InFlight; got != 0 {
+		t.Fatalf("want no in flight dispatch, got %d", got)
+	}
+}
@@ -0,0 +1,99 @@
+package inapp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// publication collects the callbacks result of a publish, the result is reported when all callbacks done.
+type publication struct {
+	strict    bool        // strict mode, the first callback error is the result.
+	mu        sync.Mutex  // mu protects fields below.
+	pending   int         // pending is the count of not finished callbacks and dispatch.
+	errs      Errors      // errs of callbacks in non-strict mode.
+	strictErr error       // strictErr is the first callback error in strict mode, it's ErrStrictAborted error.
+	canceled  bool        // canceled is true when publish context is done.
+	done      func(error) // done reports the publish result.
+}
+
+// new publication, the dispatch itself is pending until finish called.
+func newPublication(strict bool, done func(error)) *publication {
+	return &publication{
+		strict:  strict,
+		pending: 1,
+		done:    done,
+	}
+}
+
+// add pending callback.
+func (p *publication) add() {
+	p.mu.Lock()
+	p.pending++
+	p.mu.Unlock()
+}
+
+// record callback error without finish.
+func (p *publication) record(err error) {
+	if err == nil {
+		return
+	}
+	p.mu.Lock()
+	p.recordLocked(err)
+	p.mu.Unlock()
+}
+
+func (p *publication) recordLocked(err error) {
+	if err == nil {
+		return
+	}
+	if p.strict {
+		if p.strictErr == nil {
+			p.strictErr = fmt.Errorf("%w: %w", ErrStrictAborted, err)
+		}
+		return
+	}
+	p.errs = append(p.errs, err)
+}
+
+// cancel the remaining callbacks, the context error is recorded once as ErrPublishCanceled error.
+func (p *publication) cancel(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.canceled {
+		return
+	}
+	p.canceled = true
+	err = fmt.Errorf("%w: %w", ErrPublishCanceled, err)
+	if !p.strict {
+		p.errs = append(p.errs, err)
+	} else if p.strictErr == nil {
+		p.strictErr = err
+	}
+}
+
+// aborted reports whether a callback failed in strict mode or publish is canceled.
+func (p *publication) aborted() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.strictErr != nil || p.canceled
+}
+
+// finish a pending callback or dispatch with error, reports result when all finished.
+func (p *publication) finish(err error) {
+	p.mu.Lock()
+	p.recordLocked(err)
+	p.pending--
+	if p.pending > 0 {
+		p.mu.Unlock()
+		return
+	}
+	var result = p.strictErr
+	if result == nil {
+		result = p.errs.Nil()
+	}
+	p.mu.Unlock()
+
+	if p.done != nil {
+		p.done(result)
+	}
+}
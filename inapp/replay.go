@@ -0,0 +1,202 @@
+package inapp
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// replayBuffer is the ring buffer of recent Envelopes of event name.
+type replayBuffer struct {
+	list  []*Envelope // list of Envelopes in publish order.
+	start int         // start index of the oldest Envelope when list is full.
+}
+
+// push Envelope into buffer, the oldest is dropped when size exceeded, size is not positive is unbounded.
+func (buf *replayBuffer) push(env *Envelope, size int) {
+	if size <= 0 || len(buf.list) < size {
+		buf.list = append(buf.list, env)
+		return
+	}
+	buf.list[buf.start] = env
+	buf.start = (buf.start + 1) % len(buf.list)
+}
+
+// remove the Envelope from buffer.
+func (buf *replayBuffer) remove(env *Envelope) {
+	var list = make([]*Envelope, 0, len(buf.list))
+	for idx := range buf.list {
+		if item := buf.list[(buf.start+idx)%len(buf.list)]; item != env {
+			list = append(list, item)
+		}
+	}
+	buf.list, buf.start = list, 0
+}
+
+// envelopes returns the Envelopes in publish order, the Envelopes published before after are dropped.
+func (buf *replayBuffer) envelopes(after time.Time) []*Envelope {
+	buf.trim(after)
+	var list = make([]*Envelope, 0, len(buf.list))
+	list = append(list, buf.list[buf.start:]...)
+	list = append(list, buf.list[:buf.start]...)
+	return list
+}
+
+// trim drops the Envelopes published before after.
+func (buf *replayBuffer) trim(after time.Time) {
+	var n int
+	for n < len(buf.list) && buf.list[(buf.start+n)%len(buf.list)].Time.Before(after) {
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	if buf.start == 0 {
+		for idx := 0; idx < n; idx++ {
+			buf.list[idx] = nil
+		}
+		buf.list = buf.list[n:]
+		return
+	}
+	// compact the ring
+	var list = make([]*Envelope, 0, len(buf.list)-n)
+	for idx := n; idx < len(buf.list); idx++ {
+		list = append(list, buf.list[(buf.start+idx)%len(buf.list)])
+	}
+	buf.list, buf.start = list, 0
+}
+
+// replayEnabled reports whether the Event keeps the recent Envelopes.
+func (e *Event) replayEnabled() bool {
+	var options = e.getOptions()
+	return options.ReplaySize > 0 || options.ReplayAge > 0
+}
+
+// replayable reports whether the callback replays history on Subscribe.
+func (e *Event) replayable(cb *callback) bool {
+	if !e.replayEnabled() || cb.subscribeOptions == nil {
+		return false
+	}
+	return cb.subscribeOptions.Replay > 0 || !cb.subscribeOptions.ReplaySince.IsZero()
+}
+
+// record the Envelope in history when replay buffer enabled, it must be called with historyMu held.
+func (e *Event) record(env *Envelope) {
+	if !e.replayEnabled() {
+		return
+	}
+	if e.history == nil {
+		e.history = make(map[string]*replayBuffer)
+	}
+	var buf = e.history[env.Name]
+	if buf == nil {
+		buf = new(replayBuffer)
+		e.history[env.Name] = buf
+	}
+	var options = e.getOptions()
+	buf.push(env, options.ReplaySize)
+	if options.ReplayAge <= 0 {
+		return
+	}
+
+	var after = e.now().Add(-options.ReplayAge)
+	if buf.start == 0 {
+		// the full ring is bounded by size, it's compacted by sweep
+		buf.trim(after)
+	}
+	// sweep the expired Envelopes of all names when recorded count reached the names count
+	if e.recorded++; e.recorded >= len(e.history) {
+		e.recorded = 0
+		for name, buf := range e.history {
+			if buf.trim(after); len(buf.list) == 0 {
+				delete(e.history, name)
+			}
+		}
+	}
+}
+
+// forget the recorded Envelope in history.
+func (e *Event) forget(env *Envelope) {
+	if !e.replayEnabled() {
+		return
+	}
+	e.historyMu.Lock()
+	if buf := e.history[env.Name]; buf != nil {
+		buf.remove(env)
+	}
+	e.historyMu.Unlock()
+}
+
+// recent returns the recent Envelopes which matched the subscribed name in publish order, it must be called with historyMu held.
+// the Envelopes are limited by the subscribed Replay count and ReplaySince time.
+func (e *Event) recent(name string, subscribeOptions *SubscribeOptions) []*Envelope {
+	var after = subscribeOptions.ReplaySince
+	if age := e.getOptions().ReplayAge; age > 0 {
+		if expired := e.now().Add(-age); expired.After(after) {
+			after = expired
+		}
+	}
+
+	var list []*Envelope
+	for key, buf := range e.history {
+		if key == name || MatchTopic(name, key) {
+			list = append(list, buf.envelopes(after)...)
+			if len(buf.list) == 0 {
+				delete(e.history, key)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Sequence < list[j].Sequence
+	})
+
+	if n := subscribeOptions.Replay; n > 0 && len(list) > n {
+		list = list[len(list)-n:]
+	}
+	return list
+}
+
+// deliverReplay done the pending history of the new subscribed callback by Executor.
+// the history is done by the first dispatch to callback when it's earlier.
+func (e *Event) deliverReplay(event *event, cb *callback) {
+	if len(cb.pending) == 0 {
+		return
+	}
+	e.getOptions().Executor.Execute(func() {
+		doneLock, _ := e.lock(context.Background(), event)
+		if doneLock == nil { // event removed
+			return
+		}
+		defer e.unlock(event, doneLock)
+
+		e.replay(event, cb)
+	})
+}
+
+// replay the pending history to callback in publish order, it must be called with event doneLock held.
+// returns true when any history delivered, the failed history is put into the DeadLetterSink.
+func (e *Event) replay(event *event, cb *callback) bool {
+	var list = cb.pending
+	if len(list) == 0 {
+		return false
+	}
+	cb.pending = nil
+
+	var delivered bool
+	for _, env := range list {
+		if !cb.active() || cb.exhausted(e.now()) {
+			break
+		}
+		var ctx = context.WithValue(context.Background(), topicCtxKey{}, env.Name)
+		ctx = context.WithValue(ctx, envelopeCtxKey{}, env)
+		if !e.filter(ctx, cb, env.Payload...) {
+			continue
+		}
+		e.deliverEnvelope(ctx, event, cb, env)
+		delivered = true
+		if cb.subscribeOptions.Once {
+			break
+		}
+	}
+	return delivered
+}
@@ -0,0 +1,203 @@
+package inapp
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEvent_SubscribeReplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []EventOption
+		publish []string
+		wait    time.Duration // wait before the last publish
+		since   bool          // replay since the last publish
+		topic   string
+		opts    []SubscribeOption
+		live    []string // live publish after subscribed
+		want    []string
+	}{
+		{
+			name:    "last n",
+			options: []EventOption{WithReplayBuffer(10, 0)},
+			publish: []string{"a.x=1", "a.x=2", "a.x=3", "a.x=4"},
+			topic:   "a.x",
+			opts:    []SubscribeOption{WithReplayOption(2)},
+			want:    []string{"a.x=3", "a.x=4"},
+		},
+		{
+			name:    "bounded by size",
+			options: []EventOption{WithReplayBuffer(3, 0)},
+			publish: []string{"a.x=1", "a.x=2", "a.x=3", "a.x=4", "a.x=5"},
+			topic:   "a.x",
+			opts:    []SubscribeOption{WithReplayOption(10)},
+			want:    []string{"a.x=3", "a.x=4", "a.x=5"},
+		},
+		{
+			name:    "bounded by age",
+			options: []EventOption{WithReplayBuffer(0, time.Millisecond*50)},
+			publish: []string{"a.x=1", "a.x=2", "a.x=3"},
+			wait:    time.Millisecond * 100,
+			topic:   "a.x",
+			opts:    []SubscribeOption{WithReplayOption(10)},
+			want:    []string{"a.x=3"},
+		},
+		{
+			name:    "since",
+			options: []EventOption{WithReplayBuffer(10, 0)},
+			publish: []string{"a.x=1", "a.x=2", "a.x=3"},
+			wait:    time.Millisecond * 20,
+			since:   true,
+			topic:   "a.x",
+			opts:    []SubscribeOption{WithReplayOption(10)},
+			want:    []string{"a.x=3"},
+		},
+		{
+			name:    "wildcard",
+			options: []EventOption{WithReplayBuffer(10, 0)},
+			publish: []string{"a.x=1", "a.y=2", "b.x=3", "a.x=4"},
+			topic:   "a.*",
+			opts:    []SubscribeOption{WithReplayOption(10)},
+			want:    []string{"a.x=1", "a.y=2", "a.x=4"},
+		},
+		{
+			name:    "once",
+			options: []EventOption{WithReplayBuffer(10, 0)},
+			publish: []string{"a.x=1", "a.x=2"},
+			topic:   "a.x",
+			opts:    []SubscribeOption{WithReplayOption(10), WithOnceOption(true)},
+			want:    []string{"a.x=1"},
+		},
+		{
+			name:    "once history filtered",
+			options: []EventOption{WithReplayBuffer(10, 0), WithExecutor(GoExecutor{})},
+			publish: []string{"a.x=1"},
+			topic:   "a.x",
+			opts: []SubscribeOption{WithReplayOption(10), WithOnceOption(true), WithFilterOption(func(ctx context.Context, args ...interface{}) bool {
+				return args[0] != "1"
+			})},
+			live: []string{"a.x=2", "a.x=3"},
+			want: []string{"a.x=2"},
+		},
+		{
+			name:    "disabled",
+			publish: []string{"a.x=1", "a.x=2"},
+			topic:   "a.x",
+			opts:    []SubscribeOption{WithReplayOption(10)},
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEvent(append([]EventOption{WithExecutor(InlineExecutor{})}, tt.options...)...)
+			var opts = tt.opts
+			for idx, item := range tt.publish {
+				if idx == len(tt.publish)-1 {
+					time.Sleep(tt.wait)
+					if tt.since {
+						opts = append(opts, WithReplaySinceOption(time.Now()))
+					}
+				}
+				var topic, value = item[:3], item[4:]
+				e.PublishSync(context.TODO(), topic, value)
+			}
+
+			var mu sync.Mutex
+			var got []string
+			e.Subscribe(NewSubscribeOptionContext(context.TODO(), opts...), tt.topic, func(ctx context.Context, args ...interface{}) error {
+				topic, _ := GetTopicFromContext(ctx)
+				mu.Lock()
+				got = append(got, topic+"="+args[0].(string))
+				mu.Unlock()
+				return nil
+			})
+			for _, item := range tt.live {
+				var topic, value = item[:3], item[4:]
+				e.PublishSync(context.TODO(), topic, value)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEvent_SubscribeReplayHandOver(t *testing.T) {
+	const count = 2000
+
+	e := NewEvent(WithReplayBuffer(count, 0))
+
+	var mu sync.Mutex
+	var got []int
+	var done = make(chan struct{})
+	var published = make(chan struct{})
+
+	go func() {
+		defer close(published)
+		for i := 1; i <= count; i++ {
+			if i == count/2 {
+				close(done)
+			}
+			e.PublishSync(context.TODO(), "counter", i)
+		}
+	}()
+
+	// subscribe in the middle of publish
+	<-done
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithReplayOption(count)), "counter", func(ctx context.Context, args ...interface{}) error {
+		mu.Lock()
+		got = append(got, args[0].(int))
+		mu.Unlock()
+		return nil
+	})
+	<-published
+
+	var deadline = time.Now().Add(time.Second * 5)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= count || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != count {
+		t.Fatalf("want %d deliveries, got %d", count, len(got))
+	}
+	for idx, value := range got {
+		if value != idx+1 {
+			t.Fatalf("want %d at %d, got %d", idx+1, idx, value)
+		}
+	}
+}
+
+func TestEvent_ReplayBufferExpired(t *testing.T) {
+	var clock = newFakeClock()
+	var e = NewEvent(WithReplayBuffer(0, time.Second), WithClock(clock))
+
+	e.PublishSync(context.TODO(), "b.x", 0)
+	for i := 0; i < 1000; i++ {
+		e.PublishSync(context.TODO(), "a.x", i)
+		clock.Advance(time.Second)
+	}
+
+	e.historyMu.Lock()
+	defer e.historyMu.Unlock()
+	// the publish of age ago is kept
+	if got := len(e.history["a.x"].list); got != 2 {
+		t.Fatalf("want 2 recent, got %d", got)
+	}
+	if _, ok := e.history["b.x"]; ok {
+		t.Fatalf("want expired b.x history removed")
+	}
+}
@@ -0,0 +1,128 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrNoResponder        = errors.New("no responder")
+	ErrMultipleResponders = errors.New("multiple responders")
+)
+
+// Responder is the callback of responder subscription, it returns the reply of request.
+type Responder func(ctx context.Context, args ...interface{}) (interface{}, error)
+
+// replyCtxKey is the context key of request reply.
+type replyCtxKey struct{}
+
+// reply of request, it's set by the responder callback.
+type reply struct {
+	name    string        // name is the request event name.
+	claimed int32         // claimed is set to 1 by the request publish.
+	mu      sync.Mutex    // mu protects fields below.
+	called  bool          // called is true when responder done.
+	value   interface{}   // value is the responder result.
+	err     error         // err is the last responder error.
+	done    chan struct{} // done is closed when responder succeeded.
+}
+
+// claim the reply by publish of name, returns false when it's not the request publish.
+func (r *reply) claim(name string) bool {
+	return name == r.name && atomic.CompareAndSwapInt32(&r.claimed, 0, 1)
+}
+
+// set the responder result, the first succeeded result is the reply.
+func (r *reply) set(value interface{}, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.done: // replied
+		return
+	default:
+	}
+	r.called = true
+	r.value, r.err = value, err
+	if err == nil {
+		close(r.done)
+	}
+}
+
+// Respond subscribe event with name and responder f, passed option by context, returns the Subscription handle.
+// f is done as a subscribed callback, its result is the reply of Request, it's ignored when the event is published.
+// the responder error is the callback error, so it can be retried by WithRetryOption.
+func (e *Event) Respond(ctx context.Context, name string, f Responder) *Subscription {
+	if f == nil {
+		return nil
+	}
+	cb := newCallback(atomic.AddUint64(&e.seq, 1), name, func(ctx context.Context, args ...interface{}) error {
+		value, err := f(ctx, args...)
+		if r, _ := ctx.Value(replyCtxKey{}).(*reply); r != nil {
+			r.set(value, err)
+		}
+		return err
+	}, GetSubscribeOptionsFromContext(ctx))
+	cb.responder = true
+	return e.subscribe(cb)
+}
+
+// Request publish event with args and publish option by context, returns the reply of the only responder.
+// the other subscribers are done as PublishSync, the reply is returned without waiting them.
+// returns ErrNoResponder when no responder subscribed or done, ErrMultipleResponders when more than one responder subscribed.
+// returns the context error when ctx is done before reply, otherwise the responder error.
+func (e *Event) Request(ctx context.Context, name string, args ...interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch e.responders(name) {
+	case 0:
+		return nil, ErrNoResponder
+	case 1:
+	default:
+		return nil, ErrMultipleResponders
+	}
+
+	var r = &reply{
+		name: name,
+		done: make(chan struct{}),
+	}
+	var published = make(chan error, 1)
+	go func() {
+		published <- e.PublishSync(context.WithValue(ctx, replyCtxKey{}, r), name, args...)
+	}()
+
+	select {
+	case <-r.done:
+		return r.value, nil
+	case err := <-published:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		switch {
+		case !r.called && (err == nil || err == ErrNotExistEvent):
+			// responder unsubscribed or skipped
+			return nil, ErrNoResponder
+		case !r.called:
+			return nil, err
+		}
+		return r.value, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// responders returns the count of active responders which matched name.
+func (e *Event) responders(name string) int {
+	var n int
+	for _, event := range e.match(name) {
+		event.mu.Lock()
+		for _, cb := range event.callbacks {
+			if cb.responder && !cb.remove && cb.active() {
+				n++
+			}
+		}
+		event.mu.Unlock()
+	}
+	return n
+}
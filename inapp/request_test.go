@@ -0,0 +1,137 @@
+package inapp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEvent_Request(t *testing.T) {
+	quote := func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		return args[0].(int) * 2, nil
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(e *Event)
+		timeout time.Duration
+		want    interface{}
+		wantErr error
+	}{
+		{
+			name: "reply",
+			setup: func(e *Event) {
+				e.Respond(context.TODO(), "pricing.quote", quote)
+				e.Subscribe(context.TODO(), "pricing.*", func(ctx context.Context, args ...interface{}) error {
+					return ErrTest // observer error is ignored
+				})
+			},
+			want: 42,
+		},
+		{
+			name:    "no responder",
+			setup:   func(e *Event) {},
+			wantErr: ErrNoResponder,
+		},
+		{
+			name: "observer only",
+			setup: func(e *Event) {
+				e.Subscribe(context.TODO(), "pricing.quote", f1)
+			},
+			wantErr: ErrNoResponder,
+		},
+		{
+			name: "multiple responders",
+			setup: func(e *Event) {
+				e.Respond(context.TODO(), "pricing.quote", quote)
+				e.Respond(context.TODO(), "pricing.*", quote)
+			},
+			wantErr: ErrMultipleResponders,
+		},
+		{
+			name: "responder error",
+			setup: func(e *Event) {
+				e.Respond(context.TODO(), "pricing.quote", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+					return nil, ErrTest
+				})
+			},
+			wantErr: ErrTest,
+		},
+		{
+			name: "retried responder",
+			setup: func(e *Event) {
+				var n int
+				e.Respond(NewSubscribeOptionContext(context.TODO(), WithRetryOption(RetryPolicy{MaxAttempts: 2})), "pricing.quote", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+					if n++; n == 1 {
+						return nil, ErrTest
+					}
+					return quote(ctx, args...)
+				})
+			},
+			want: 42,
+		},
+		{
+			name: "timeout",
+			setup: func(e *Event) {
+				e.Respond(context.TODO(), "pricing.quote", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				})
+			},
+			timeout: time.Millisecond * 20,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "nested publish",
+			setup: func(e *Event) {
+				e.Respond(context.TODO(), "pricing.tax", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+					return "tax", nil
+				})
+				e.Respond(context.TODO(), "pricing.quote", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+					// the nested responder does not reply the request
+					if err := e.PublishSync(ctx, "pricing.tax"); err != nil {
+						return nil, err
+					}
+					return quote(ctx, args...)
+				})
+			},
+			want: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e = NewEvent()
+			tt.setup(e)
+
+			var ctx = context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			got, err := e.Request(ctx, "pricing.quote", 21)
+			if err != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEvent_RespondPublish(t *testing.T) {
+	var e = NewEvent()
+	var got []interface{}
+	e.Respond(context.TODO(), "test", func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		got = append(got, args[0])
+		return "ignored", nil
+	})
+	if err := e.PublishSync(context.TODO(), "test", 1); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != 1 {
+		t.Fatalf("want [1], got %v", got)
+	}
+}
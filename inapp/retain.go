@@ -0,0 +1,56 @@
+package inapp
+
+import (
+	"context"
+	"sort"
+)
+
+// Retained returns the retained Envelope of event name.
+func (e *Event) Retained(name string) (*Envelope, bool) {
+	actual, ok := e.retained.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return actual.(*Envelope), true
+}
+
+// ClearRetained clear the retained Envelope of event name, the later subscribers will not receive it.
+func (e *Event) ClearRetained(name string) {
+	e.retained.Delete(name)
+}
+
+// deliverRetained done the new subscribed callback with the retained Envelopes which matched the event name.
+// the Envelopes are done in publish order by Executor, they are dropped when Executor can't accept.
+func (e *Event) deliverRetained(event *event, cb *callback) {
+	var list []*Envelope
+	if IsWildcardTopic(event.name) {
+		e.retained.Range(func(key, value interface{}) bool {
+			if MatchTopic(event.name, key.(string)) {
+				list = append(list, value.(*Envelope))
+			}
+			return true
+		})
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Sequence < list[j].Sequence
+		})
+	} else if env, ok := e.Retained(event.name); ok {
+		list = append(list, env)
+	}
+	if len(list) == 0 {
+		return
+	}
+
+	e.getOptions().Executor.Execute(func() {
+		for _, env := range list {
+			if !cb.active() {
+				return
+			}
+			var ctx = context.WithValue(context.Background(), topicCtxKey{}, env.Name)
+			ctx = context.WithValue(ctx, envelopeCtxKey{}, env)
+
+			var p = newPublication(false, nil)
+			e.dispatch(ctx, event, cb, p, "", env.Payload...)
+			p.finish(nil)
+		}
+	})
+}
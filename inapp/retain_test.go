@@ -0,0 +1,111 @@
+package inapp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvent_PublishRetain(t *testing.T) {
+	var got = make(chan string, 8)
+
+	record := func(ctx context.Context, args ...interface{}) error {
+		topic, _ := GetTopicFromContext(ctx)
+		got <- topic + "=" + args[0].(string)
+		return nil
+	}
+	receive := func(n int) []string {
+		var list []string
+		for i := 0; i < n; i++ {
+			select {
+			case item := <-got:
+				list = append(list, item)
+			case <-time.After(time.Second):
+				t.Fatalf("want %d deliveries, got %v", n, list)
+			}
+		}
+		select {
+		case item := <-got:
+			t.Fatalf("want %d deliveries, got more %s", n, item)
+		case <-time.After(time.Millisecond * 20):
+		}
+		return list
+	}
+
+	e := NewEvent(WithExecutor(InlineExecutor{}))
+	retain := NewPublishOptionContext(context.TODO(), WithRetainOption(true))
+
+	// retained without subscriber
+	if err := e.Publish(retain, "config.db", "v1"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := e.Publish(retain, "config.db", "v2"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := e.Publish(retain, "config.cache", "v1"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := e.Publish(context.TODO(), "config.cache", "v2"); err != ErrNotExistEvent {
+		t.Fatalf("want error %v, got %v", ErrNotExistEvent, err)
+	}
+
+	e.Subscribe(context.TODO(), "config.db", record)
+	if want := []string{"config.db=v2"}; !reflect.DeepEqual(receive(1), want) {
+		t.Fatalf("want %v", want)
+	}
+
+	e.Subscribe(context.TODO(), "config.*", record)
+	if want := []string{"config.db=v2", "config.cache=v1"}; !reflect.DeepEqual(receive(2), want) {
+		t.Fatalf("want %v", want)
+	}
+
+	// once subscriber receives retained only.
+	s := e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithOnceOption(true)), "config.cache", record)
+	if want := []string{"config.cache=v1"}; !reflect.DeepEqual(receive(1), want) {
+		t.Fatalf("want %v", want)
+	}
+	if s.Active() {
+		t.Fatalf("want once subscription inactive")
+	}
+
+	if env, ok := e.Retained("config.db"); !ok || env.Payload[0] != "v2" {
+		t.Fatalf("want retained v2, got %v", env)
+	}
+	e.ClearRetained("config.db")
+	if _, ok := e.Retained("config.db"); ok {
+		t.Fatalf("want retained cleared")
+	}
+	e.Subscribe(context.TODO(), "config.db", record)
+	receive(0)
+}
+
+func TestEvent_PublishRetainRejected(t *testing.T) {
+	var pool = NewPoolExecutor(1, 1)
+	var e = NewEvent(WithExecutor(pool), WithReplayBuffer(10, 0))
+	e.Subscribe(context.TODO(), "config.db", func(ctx context.Context, args ...interface{}) error {
+		return nil
+	})
+	retain := NewPublishOptionContext(context.TODO(), WithRetainOption(true))
+
+	if err := e.Publish(retain, "config.db", "v1"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	pool.Close()
+	if err := e.Publish(retain, "config.db", "v2"); err != ErrExecutorClosed {
+		t.Fatalf("want error %v, got %v", ErrExecutorClosed, err)
+	}
+
+	if env, ok := e.Retained("config.db"); !ok || env.Payload[0] != "v1" {
+		t.Fatalf("want retained v1, got %v", env)
+	}
+	e.historyMu.Lock()
+	var recent = e.recent("config.db", &SubscribeOptions{Replay: 10})
+	e.historyMu.Unlock()
+	if len(recent) != 1 || recent[0].Payload[0] != "v1" {
+		t.Fatalf("want history v1, got %v", recent)
+	}
+	if got := e.Stats().Published; got != 1 {
+		t.Fatalf("want published 1, got %d", got)
+	}
+}
@@ -0,0 +1,113 @@
+package inapp
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Backoff returns the wait duration before the retry, retry starts from 1.
+type Backoff func(retry int) time.Duration
+
+// ConstantBackoff waits d before every retry.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(retry int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff waits base*2^(retry-1) before retry, it's limited by max when max is positive.
+// jitter in [0, 1] randomly reduces the wait by up to the jitter fraction.
+func ExponentialBackoff(base, max time.Duration, jitter float64) Backoff {
+	return func(retry int) time.Duration {
+		var d = base
+		for i := 1; i < retry && (max <= 0 || d < max); i++ {
+			d *= 2
+		}
+		if max > 0 && d > max {
+			d = max
+		}
+		if jitter > 0 {
+			if jitter > 1 {
+				jitter = 1
+			}
+			d -= time.Duration(jitter * rand.Float64() * float64(d))
+		}
+		return d
+	}
+}
+
+// RetryPolicy of callback, retry the failed callback in the same publish.
+type RetryPolicy struct {
+	MaxAttempts int              // MaxAttempts is the max count of callback done include the first, less than 2 is no retry.
+	Backoff     Backoff          // Backoff returns the wait before retry, nil is no wait.
+	Retryable   func(error) bool // Retryable reports whether the callback error can be retried, nil is all.
+}
+
+// RetryError is the callback error after retried.
+type RetryError struct {
+	Attempts int    // Attempts is the count of callback done.
+	Errors   Errors // Errors of every attempt, the last is the context error when retry canceled by publish context.
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("retry %d attempts: %v", e.Attempts, e.Unwrap())
+}
+
+// Unwrap returns the last error.
+func (e *RetryError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[len(e.Errors)-1]
+}
+
+// retry call f by policy until succeed, the wait between attempts is timed by clock and canceled by ctx.
+// returns the RetryError when retried and failed, otherwise the f error.
+func (policy *RetryPolicy) retry(ctx context.Context, clock Clock, f func() error) error {
+	var err = f()
+	if err == nil || policy == nil || policy.MaxAttempts < 2 {
+		return err
+	}
+
+	var retryErr = &RetryError{
+		Attempts: 1,
+		Errors:   Errors{err},
+	}
+	for retryErr.Attempts < policy.MaxAttempts {
+		if policy.Retryable != nil && !policy.Retryable(err) {
+			break
+		}
+		if policy.Backoff != nil {
+			if d := policy.Backoff(retryErr.Attempts); d > 0 {
+				var wait = make(chan struct{})
+				timer := clock.AfterFunc(d, func() {
+					close(wait)
+				})
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					retryErr.Errors = append(retryErr.Errors, ctx.Err())
+					return retryErr
+				case <-wait:
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			retryErr.Errors = append(retryErr.Errors, ctx.Err())
+			return retryErr
+		}
+
+		retryErr.Attempts++
+		if err = f(); err == nil {
+			return nil
+		}
+		retryErr.Errors = append(retryErr.Errors, err)
+	}
+
+	if retryErr.Attempts == 1 {
+		return err
+	}
+	return retryErr
+}
@@ -0,0 +1,172 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Millisecond, time.Millisecond*5, 0)
+	for retry, want := range []time.Duration{0, 1, 2, 4, 5, 5} {
+		if retry == 0 {
+			continue
+		}
+		if got := backoff(retry); got != want*time.Millisecond {
+			t.Errorf("backoff(%d) = %v, want %v", retry, got, want*time.Millisecond)
+		}
+	}
+
+	backoff = ExponentialBackoff(time.Millisecond*100, 0, 0.5)
+	for i := 0; i < 100; i++ {
+		if got := backoff(2); got < time.Millisecond*100 || got > time.Millisecond*200 {
+			t.Fatalf("backoff(2) = %v, want in [100ms, 200ms]", got)
+		}
+	}
+}
+
+func TestEvent_PublishRetry(t *testing.T) {
+	var attempts int
+
+	newFailure := func(failures int, err error) func(context.Context, ...interface{}) error {
+		return func(ctx context.Context, args ...interface{}) error {
+			attempts++
+			if attempts <= failures {
+				return err
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		f            func(context.Context, ...interface{}) error
+		ctx          func() (context.Context, context.CancelFunc)
+		wantAttempts int
+		result       func(error) error
+	}{
+		{
+			name:         "succeed after retry",
+			policy:       RetryPolicy{MaxAttempts: 3, Backoff: ConstantBackoff(time.Millisecond)},
+			f:            newFailure(2, ErrTest),
+			wantAttempts: 3,
+		},
+		{
+			name:         "failed after max attempts",
+			policy:       RetryPolicy{MaxAttempts: 3, Backoff: ExponentialBackoff(time.Millisecond, 0, 0.2)},
+			f:            newFailure(5, ErrTest),
+			wantAttempts: 3,
+			result: func(err error) error {
+				var retryErr *RetryError
+				if !errors.As(err, &retryErr) || retryErr.Attempts != 3 || len(retryErr.Errors) != 3 || !errors.Is(err, ErrTest) {
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			name: "not retryable",
+			policy: RetryPolicy{MaxAttempts: 3, Retryable: func(err error) bool {
+				return err != ErrTest
+			}},
+			f:            newFailure(5, ErrTest),
+			wantAttempts: 1,
+			result: func(err error) error {
+				if !errors.Is(err, ErrTest) {
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			name:         "panic retried",
+			policy:       RetryPolicy{MaxAttempts: 2},
+			f:            func(ctx context.Context, args ...interface{}) error { attempts++; panic(ErrPanic) },
+			wantAttempts: 2,
+			result: func(err error) error {
+				if !errors.Is(err, ErrPanic) {
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			name:   "canceled by publish context",
+			policy: RetryPolicy{MaxAttempts: 3, Backoff: ConstantBackoff(time.Hour)},
+			f:      newFailure(5, ErrTest),
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.TODO(), time.Millisecond*10)
+			},
+			wantAttempts: 1,
+			result: func(err error) error {
+				var retryErr *RetryError
+				if !errors.As(err, &retryErr) || retryErr.Attempts != 1 || !errors.Is(err, context.DeadlineExceeded) {
+					return err
+				}
+				return nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts = 0
+			var ctx, cancel = context.TODO(), context.CancelFunc(func() {})
+			if tt.ctx != nil {
+				ctx, cancel = tt.ctx()
+			}
+			defer cancel()
+
+			e := NewEvent()
+			e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithRetryOption(tt.policy)), "test", tt.f)
+
+			err := e.PublishSync(NewPublishOptionContext(ctx, WithStrictModeOption(true)), "test")
+			if attempts != tt.wantAttempts {
+				t.Errorf("want attempts %d, got %d", tt.wantAttempts, attempts)
+			}
+			if tt.result == nil {
+				if err != nil {
+					t.Errorf("PublishSync() error = %v", err)
+				}
+			} else if err := tt.result(err); err != nil {
+				t.Errorf("PublishSync() error %v", err)
+			}
+		})
+	}
+}
+
+func TestEvent_PublishRetryClock(t *testing.T) {
+	var clock = newFakeClock()
+	var e = NewEvent(WithClock(clock))
+	var attempts int32
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithRetryOption(RetryPolicy{MaxAttempts: 2, Backoff: ConstantBackoff(time.Hour)})), "test", func(ctx context.Context, args ...interface{}) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return ErrTest
+		}
+		return nil
+	})
+
+	var result = make(chan error, 1)
+	go func() {
+		result <- e.PublishSync(context.TODO(), "test")
+	}()
+
+	// the backoff waits the clock
+	for clock.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("want 1 attempt before backoff, got %d", got)
+	}
+	clock.Advance(time.Hour)
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("PublishSync() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("want retried after clock advanced")
+	}
+}
@@ -0,0 +1,23 @@
+package inapp
+
+import (
+	"sync/atomic"
+)
+
+// Stats is the counters of Event.
+type Stats struct {
+	Published uint64 // Published is the count of publish.
+	Delivered uint64 // Delivered is the count of callback deliveries include failed, the retry attempts of a delivery are counted once.
+	Failed    uint64 // Failed is the count of callback failed after retried.
+	Filtered  uint64 // Filtered is the count of callback skipped by subscribed filter.
+}
+
+// Stats returns the snapshot of Event counters.
+func (e *Event) Stats() Stats {
+	return Stats{
+		Published: atomic.LoadUint64(&e.stats.Published),
+		Delivered: atomic.LoadUint64(&e.stats.Delivered),
+		Failed:    atomic.LoadUint64(&e.stats.Failed),
+		Filtered:  atomic.LoadUint64(&e.stats.Filtered),
+	}
+}
@@ -0,0 +1,36 @@
+package inapp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEvent_Stats(t *testing.T) {
+	var e = NewEvent()
+	e.Subscribe(context.TODO(), "test", func(ctx context.Context, args ...interface{}) error {
+		if args[0].(int) == 3 {
+			return ErrTest
+		}
+		return nil
+	})
+	e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithFilterOption(func(ctx context.Context, args ...interface{}) bool {
+		return args[0].(int) > 2
+	})), "test", func(ctx context.Context, args ...interface{}) error {
+		return nil
+	})
+
+	for i := 1; i <= 4; i++ {
+		e.PublishSync(context.TODO(), "test", i)
+	}
+	e.PublishSync(context.TODO(), "none", 0)
+
+	var want = Stats{
+		Published: 5,
+		Delivered: 6,
+		Failed:    1,
+		Filtered:  2,
+	}
+	if got := e.Stats(); got != want {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,90 @@
+package inapp
+
+import (
+	"sync"
+)
+
+// Subscription is a subscribed callback handle, returned by Subscribe.
+type Subscription struct {
+	e    *Event    // e is the Event which subscribed.
+	name string    // name is the subscribed event name.
+	cb   *callback // cb is the subscribed callback.
+}
+
+// new Subscription.
+func newSubscription(e *Event, name string, cb *callback) *Subscription {
+	return &Subscription{
+		e:    e,
+		name: name,
+		cb:   cb,
+	}
+}
+
+// ID returns the unique id of Subscription in Event.
+func (s *Subscription) ID() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.cb.id
+}
+
+// Event returns the subscribed event name.
+func (s *Subscription) Event() string {
+	if s == nil {
+		return ""
+	}
+	return s.name
+}
+
+// Active reports whether the Subscription is still subscribed.
+func (s *Subscription) Active() bool {
+	if s == nil {
+		return false
+	}
+	return s.cb.active()
+}
+
+// Unsubscribe the Subscription callback, it's safe to call more than once.
+func (s *Subscription) Unsubscribe() {
+	if s == nil || !s.cb.active() {
+		return
+	}
+	s.e.remove(s.cb)
+}
+
+// SubscriptionSet is a Subscription set, use it to Unsubscribe many Subscription at once.
+// the zero value is ready to use.
+type SubscriptionSet struct {
+	mu   sync.Mutex      // mu protects list.
+	list []*Subscription // list of added Subscription.
+}
+
+// Add Subscription into set, nil Subscription will be ignored.
+func (set *SubscriptionSet) Add(subs ...*Subscription) {
+	set.mu.Lock()
+	for _, s := range subs {
+		if s != nil {
+			set.list = append(set.list, s)
+		}
+	}
+	set.mu.Unlock()
+}
+
+// Len returns the count of Subscription in set.
+func (set *SubscriptionSet) Len() int {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	return len(set.list)
+}
+
+// Unsubscribe all Subscription in set and empty the set.
+func (set *SubscriptionSet) Unsubscribe() {
+	set.mu.Lock()
+	list := set.list
+	set.list = nil
+	set.mu.Unlock()
+
+	for _, s := range list {
+		s.Unsubscribe()
+	}
+}
@@ -0,0 +1,187 @@
+package inapp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscription_Unsubscribe(t *testing.T) {
+	var (
+		count int
+		errCh = make(chan error)
+	)
+
+	// closures built from the same func literal.
+	newCallback := func(step int) func(context.Context, ...interface{}) error {
+		return func(ctx context.Context, args ...interface{}) error {
+			count += step
+			return nil
+		}
+	}
+
+	e := NewEvent()
+	s1 := e.Subscribe(context.TODO(), "test", newCallback(1))
+	s2 := e.Subscribe(context.TODO(), "test", newCallback(10))
+
+	if s1.ID() == s2.ID() {
+		t.Fatalf("want unique id, got %d and %d", s1.ID(), s2.ID())
+	}
+	if s1.Event() != "test" {
+		t.Fatalf("want event test, got %s", s1.Event())
+	}
+	if !s1.Active() || !s2.Active() {
+		t.Fatalf("want active subscriptions")
+	}
+
+	ctx := NewPublishOptionContext(context.TODO(), WithErrorOption(errCh))
+	if err := e.Publish(ctx, "test"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if count != 11 {
+		t.Fatalf("want count 11, got %d", count)
+	}
+
+	s1.Unsubscribe()
+	s1.Unsubscribe()
+	if s1.Active() {
+		t.Fatalf("want s1 inactive")
+	}
+	if !s2.Active() {
+		t.Fatalf("want s2 active")
+	}
+
+	if err := e.Publish(ctx, "test"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if count != 21 {
+		t.Fatalf("want count 21, got %d", count)
+	}
+
+	s2.Unsubscribe()
+	if _, ok := e.list.Load("test"); ok {
+		t.Fatalf("should be remove the test event")
+	}
+}
+
+func TestSubscription_Once(t *testing.T) {
+	var errCh = make(chan error)
+
+	e := NewEvent()
+	s := e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithOnceOption(true)), "test", f1)
+
+	if err := e.Publish(NewPublishOptionContext(context.TODO(), WithErrorOption(errCh)), "test"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	<-errCh
+	if s.Active() {
+		t.Fatalf("want once subscription inactive after publish")
+	}
+}
+
+func TestSubscription_MaxDeliveries(t *testing.T) {
+	var e = NewEvent()
+	var count int
+	s := e.Subscribe(NewSubscribeOptionContext(context.TODO(), WithMaxDeliveriesOption(2)), "test", func(ctx context.Context, args ...interface{}) error {
+		count++
+		if args[0] == "fail" {
+			return ErrTest
+		}
+		return nil
+	})
+
+	// the failed delivery is not counted
+	for _, arg := range []string{"ok", "fail", "ok", "ok"} {
+		e.PublishSync(context.TODO(), "test", arg)
+	}
+	if count != 3 {
+		t.Fatalf("want count 3, got %d", count)
+	}
+	if s.Active() {
+		t.Fatalf("want inactive after max deliveries")
+	}
+	if _, ok := e.list.Load("test"); ok {
+		t.Fatalf("want event removed")
+	}
+}
+
+func TestSubscription_Expiry(t *testing.T) {
+	var e = NewEvent()
+	var count int
+	expiry := NewSubscribeOptionContext(context.TODO(), WithExpiryOption(time.Now().Add(time.Millisecond*50)))
+	s := e.Subscribe(expiry, "test", func(ctx context.Context, args ...interface{}) error {
+		count++
+		return nil
+	})
+	e.Subscribe(context.TODO(), "test", f1)
+
+	if err := e.PublishSync(context.TODO(), "test", new(int)); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("want count 1, got %d", count)
+	}
+
+	// removed without publish
+	time.Sleep(time.Millisecond * 100)
+	if s.Active() {
+		t.Fatalf("want inactive after expired")
+	}
+	actual, _ := e.list.Load("test")
+	actual.(*event).mu.Lock()
+	if list := actual.(*event).callbacks; len(list) != 1 {
+		t.Fatalf("want 1 callback, got %d", len(list))
+	}
+	actual.(*event).mu.Unlock()
+
+	e.PublishSync(context.TODO(), "test", new(int))
+	if count != 1 {
+		t.Fatalf("want count 1, got %d", count)
+	}
+
+	// expired subscription without other callbacks removes event
+	e.Subscribe(expiry, "expired", f1)
+	time.Sleep(time.Millisecond * 20)
+	if _, ok := e.list.Load("expired"); ok {
+		t.Fatalf("want event removed")
+	}
+}
+
+func TestSubscriptionSet_Unsubscribe(t *testing.T) {
+	var set SubscriptionSet
+
+	e := NewEvent()
+	set.Add(
+		e.Subscribe(context.TODO(), "test", f1),
+		e.Subscribe(context.TODO(), "test", f1),
+		e.Subscribe(context.TODO(), "test1", f2),
+		e.Subscribe(context.TODO(), "test1", nil),
+	)
+	keep := e.Subscribe(context.TODO(), "test1", f3)
+
+	if set.Len() != 3 {
+		t.Fatalf("want length 3, got %d", set.Len())
+	}
+
+	set.Unsubscribe()
+
+	if set.Len() != 0 {
+		t.Fatalf("want length 0, got %d", set.Len())
+	}
+	if _, ok := e.list.Load("test"); ok {
+		t.Fatalf("should be remove the test event")
+	}
+	value, ok := e.list.Load("test1")
+	if !ok {
+		t.Fatalf("should be have the test1 event")
+	}
+	if list := value.(*event).callbacks; len(list) != 1 || list[0].id != keep.ID() {
+		t.Fatalf("want only subscription %d, got %v", keep.ID(), list)
+	}
+}
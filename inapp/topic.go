@@ -0,0 +1,138 @@
+package inapp
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	TopicSeparator     = "." // TopicSeparator separates the levels of event name.
+	TopicWildcard      = "*" // TopicWildcard matches exactly one level, e.g. "order.*.created".
+	TopicMultiWildcard = "#" // TopicMultiWildcard matches zero or more levels, it must be the last level, e.g. "order.#".
+)
+
+// IsWildcardTopic reports whether the event name contains wildcard levels.
+func IsWildcardTopic(name string) bool {
+	tokens := strings.Split(name, TopicSeparator)
+	for idx, token := range tokens {
+		if token == TopicWildcard {
+			return true
+		}
+		if token == TopicMultiWildcard && idx == len(tokens)-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchTopic reports whether the concrete topic is matched by pattern.
+func MatchTopic(pattern, topic string) bool {
+	return matchTokens(strings.Split(pattern, TopicSeparator), strings.Split(topic, TopicSeparator))
+}
+
+func matchTokens(pattern, topic []string) bool {
+	for idx, token := range pattern {
+		if token == TopicMultiWildcard && idx == len(pattern)-1 {
+			return true
+		}
+		if idx >= len(topic) {
+			return false
+		}
+		if token != TopicWildcard && token != topic[idx] {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
+
+// topic tree index the wildcard event names by level.
+type topicTree struct {
+	mu   sync.RWMutex // mu protects root.
+	root *topicNode   // root level node, created on first insert.
+}
+
+// topic tree level node.
+type topicNode struct {
+	children map[string]*topicNode // next level nodes.
+	pattern  string                // pattern is the wildcard event name ended at this node.
+}
+
+// insert wildcard event name into tree.
+func (tree *topicTree) insert(pattern string) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	if tree.root == nil {
+		tree.root = &topicNode{}
+	}
+	node := tree.root
+	for _, token := range strings.Split(pattern, TopicSeparator) {
+		if node.children == nil {
+			node.children = make(map[string]*topicNode)
+		}
+		child, ok := node.children[token]
+		if !ok {
+			child = &topicNode{}
+			node.children[token] = child
+		}
+		node = child
+	}
+	node.pattern = pattern
+}
+
+// remove wildcard event name from tree, prune the empty nodes.
+// it's skipped when subscribed reports true, the check is done with tree locked to mutex with insert.
+func (tree *topicTree) remove(pattern string, subscribed func() bool) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	if tree.root == nil || (subscribed != nil && subscribed()) {
+		return
+	}
+	tree.root.remove(strings.Split(pattern, TopicSeparator))
+}
+
+// remove tokens path, returns true when node is empty.
+func (node *topicNode) remove(tokens []string) bool {
+	if len(tokens) == 0 {
+		node.pattern = ""
+	} else if child, ok := node.children[tokens[0]]; ok {
+		if child.remove(tokens[1:]) {
+			delete(node.children, tokens[0])
+		}
+	}
+	return node.pattern == "" && len(node.children) == 0
+}
+
+// match returns the wildcard event names which matched concrete topic.
+func (tree *topicTree) match(topic string) []string {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	if tree.root == nil {
+		return nil
+	}
+	var list []string
+	tree.root.match(strings.Split(topic, TopicSeparator), &list)
+	return list
+}
+
+func (node *topicNode) match(tokens []string, list *[]string) {
+	if child, ok := node.children[TopicMultiWildcard]; ok && child.pattern != "" {
+		*list = append(*list, child.pattern)
+	}
+	if len(tokens) == 0 {
+		if node.pattern != "" {
+			*list = append(*list, node.pattern)
+		}
+		return
+	}
+	if child, ok := node.children[tokens[0]]; ok {
+		child.match(tokens[1:], list)
+	}
+	if tokens[0] != TopicWildcard {
+		if child, ok := node.children[TopicWildcard]; ok {
+			child.match(tokens[1:], list)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package inapp
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{pattern: "order.created", topic: "order.created", want: true},
+		{pattern: "order.created", topic: "order.updated", want: false},
+		{pattern: "order.*", topic: "order.created", want: true},
+		{pattern: "order.*", topic: "order", want: false},
+		{pattern: "order.*", topic: "order.1.created", want: false},
+		{pattern: "order.*.created", topic: "order.1.created", want: true},
+		{pattern: "order.*.created", topic: "order.1.updated", want: false},
+		{pattern: "order.#", topic: "order", want: true},
+		{pattern: "order.#", topic: "order.1.created", want: true},
+		{pattern: "order.#", topic: "user.1", want: false},
+		{pattern: "#", topic: "user.1", want: true},
+		{pattern: "*.#", topic: "user", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.topic, func(t *testing.T) {
+			if got := MatchTopic(tt.pattern, tt.topic); got != tt.want {
+				t.Errorf("MatchTopic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_topicTree_match(t *testing.T) {
+	var tree topicTree
+	for _, pattern := range []string{"order.*", "order.#", "order.*.created", "*.1.*", "#", "user.*"} {
+		tree.insert(pattern)
+	}
+	tree.remove("user.*", nil)
+	// subscribed again is kept
+	tree.remove("order.*", func() bool { return true })
+
+	tests := []struct {
+		topic string
+		want  []string
+	}{
+		{topic: "order", want: []string{"#", "order.#"}},
+		{topic: "order.1", want: []string{"#", "order.#", "order.*"}},
+		{topic: "order.1.created", want: []string{"#", "*.1.*", "order.#", "order.*.created"}},
+		{topic: "user.1", want: []string{"#"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			got := tree.match(tt.topic)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"order.*", "order.#", "order.*.created", "*.1.*", "#"} {
+		tree.remove(pattern, nil)
+	}
+	if len(tree.root.children) != 0 {
+		t.Errorf("want empty tree, got %v", tree.root.children)
+	}
+}
+
+func TestEvent_PublishWildcard(t *testing.T) {
+	var (
+		errCh  = make(chan error)
+		topics = make(chan string, 8)
+	)
+
+	record := func(ctx context.Context, args ...interface{}) error {
+		topic, _ := GetTopicFromContext(ctx)
+		topics <- topic
+		return nil
+	}
+
+	e := NewEvent()
+	e.Subscribe(context.TODO(), "order.*.created", record)
+	s := e.Subscribe(context.TODO(), "order.#", record)
+
+	ctx := NewPublishOptionContext(context.TODO(), WithErrorOption(errCh))
+	if err := e.Publish(ctx, "order.1.created"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case topic := <-topics:
+			if topic != "order.1.created" {
+				t.Fatalf("want topic order.1.created, got %s", topic)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("want 2 deliveries, got %d", i)
+		}
+	}
+
+	if err := e.Publish(ctx, "user.1.created"); err != ErrNotExistEvent {
+		t.Fatalf("want error %v, got %v", ErrNotExistEvent, err)
+	}
+
+	s.Unsubscribe()
+	e.Unsubscribe("order.*.created")
+	if err := e.Publish(ctx, "order.1.created"); err != ErrNotExistEvent {
+		t.Fatalf("want error %v, got %v", ErrNotExistEvent, err)
+	}
+	if got := e.topics.match("order.1.created"); len(got) != 0 {
+		t.Fatalf("want empty topics, got %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrPayloadType = errors.New("payload type mismatch")
+)
+
+// Topic is a type safe event name with payload type T, it's built on Event.
+// the untyped Event API can still publish and subscribe the same name.
+type Topic[T any] struct {
+	e    *Event // e is the Event of topic.
+	name string // name is the event name.
+}
+
+// New Topic of event name with payload type T, use DefaultEvent when e is nil.
+func NewTopic[T any](e *Event, name string) *Topic[T] {
+	if e == nil {
+		e = DefaultEvent
+	}
+	return &Topic[T]{
+		e:    e,
+		name: name,
+	}
+}
+
+// Name returns the event name.
+func (t *Topic[T]) Name() string {
+	return t.name
+}
+
+// Publish payload to event, see Event.Publish.
+func (t *Topic[T]) Publish(ctx context.Context, payload T) error {
+	return t.e.Publish(ctx, t.name, payload)
+}
+
+// PublishSync payload to event, see Event.PublishSync.
+func (t *Topic[T]) PublishSync(ctx context.Context, payload T) error {
+	return t.e.PublishSync(ctx, t.name, payload)
+}
+
+// Subscribe event with typed callback func f, see Event.Subscribe.
+// f is not called and ErrPayloadType is returned when published args is not a T.
+func (t *Topic[T]) Subscribe(ctx context.Context, f func(context.Context, T) error) *Subscription {
+	if f == nil {
+		return nil
+	}
+	return t.e.Subscribe(ctx, t.name, func(ctx context.Context, args ...interface{}) error {
+		payload, err := payloadOf[T](args...)
+		if err != nil {
+			return err
+		}
+		return f(ctx, payload)
+	})
+}
+
+// payloadOf returns the only arg as T, nil arg is the zero value of T.
+func payloadOf[T any](args ...interface{}) (T, error) {
+	var payload T
+	if len(args) != 1 {
+		return payload, fmt.Errorf("%w: want 1 arg of %v, got %d args", ErrPayloadType, reflect.TypeOf(&payload).Elem(), len(args))
+	}
+	if args[0] == nil {
+		return payload, nil
+	}
+	payload, ok := args[0].(T)
+	if !ok {
+		return payload, fmt.Errorf("%w: want %v, got %T", ErrPayloadType, reflect.TypeOf(&payload).Elem(), args[0])
+	}
+	return payload, nil
+}
@@ -0,0 +1,81 @@
+package inapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type orderCreated struct {
+	ID    string
+	Total int
+}
+
+func TestTopic(t *testing.T) {
+	var got []orderCreated
+
+	e := NewEvent()
+	topic := NewTopic[orderCreated](e, "order.created")
+	s := topic.Subscribe(context.TODO(), func(ctx context.Context, order orderCreated) error {
+		got = append(got, order)
+		return nil
+	})
+
+	if err := topic.PublishSync(context.TODO(), orderCreated{ID: "1", Total: 10}); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "1" || got[0].Total != 10 {
+		t.Fatalf("want order 1, got %v", got)
+	}
+
+	// untyped publish
+	if err := e.PublishSync(context.TODO(), topic.Name(), orderCreated{ID: "2"}); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if err := e.PublishSync(context.TODO(), topic.Name(), "2"); !errors.Is(err.(Errors)[0], ErrPayloadType) {
+		t.Fatalf("want error %v, got %v", ErrPayloadType, err)
+	}
+	if err := e.PublishSync(context.TODO(), topic.Name()); !errors.Is(err.(Errors)[0], ErrPayloadType) {
+		t.Fatalf("want error %v, got %v", ErrPayloadType, err)
+	}
+	if len(got) != 2 || got[1].ID != "2" {
+		t.Fatalf("want order 2, got %v", got)
+	}
+
+	// async publish
+	var errCh = make(chan error)
+	if err := topic.Publish(NewPublishOptionContext(context.TODO(), WithErrorOption(errCh)), orderCreated{ID: "3"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if len(got) != 3 || got[2].ID != "3" {
+		t.Fatalf("want order 3, got %v", got)
+	}
+
+	s.Unsubscribe()
+	if err := topic.Publish(context.TODO(), orderCreated{}); err != ErrNotExistEvent {
+		t.Fatalf("want error %v, got %v", ErrNotExistEvent, err)
+	}
+}
+
+func TestTopic_Interface(t *testing.T) {
+	var got []error
+
+	topic := NewTopic[error](NewEvent(), "failed")
+	topic.Subscribe(context.TODO(), func(ctx context.Context, err error) error {
+		got = append(got, err)
+		return nil
+	})
+
+	if err := topic.PublishSync(context.TODO(), ErrTest); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if err := topic.PublishSync(context.TODO(), nil); err != nil {
+		t.Fatalf("PublishSync() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != ErrTest || got[1] != nil {
+		t.Fatalf("want [%v <nil>], got %v", ErrTest, got)
+	}
+}